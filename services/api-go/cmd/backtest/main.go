@@ -0,0 +1,147 @@
+// Command backtest replays historical reputation data through a candidate
+// scoring strategy and reports how it would differ from production.
+//
+//	go run ./cmd/backtest -tenant <id> -strategy bayesian -config candidate.json -from 2024-01-01
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/config"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/reputation"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	tenant := flag.String("tenant", "", "tenant ID to backtest (required)")
+	strategy := flag.String("strategy", reputation.StrategyWeighted, "candidate strategy: "+strings.Join(reputation.StrategyNames(), ", "))
+	configPath := flag.String("config", "", "JSON config file for the candidate strategy (defaults if empty)")
+	from := flag.String("from", "", "first as-of date, YYYY-MM-DD (default: 6 intervals before -to)")
+	to := flag.String("to", "", "last as-of date, YYYY-MM-DD (default: now)")
+	interval := flag.Duration("interval", 30*24*time.Hour, "time between as-of dates")
+	maxSubjects := flag.Int("max-subjects", reputation.DefaultBacktestSubjects, "subjects replayed per date")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	tenantID, err := uuid.Parse(*tenant)
+	if err != nil {
+		log.Fatalf("-tenant must be a valid UUID")
+	}
+	if *interval <= 0 {
+		log.Fatalf("-interval must be positive")
+	}
+
+	var candidateConfig json.RawMessage
+	if *configPath != "" {
+		candidateConfig, err = os.ReadFile(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to read config: %v", err)
+		}
+	}
+	candidate, err := reputation.NewStrategy(*strategy, candidateConfig)
+	if err != nil {
+		log.Fatalf("Invalid candidate strategy: %v", err)
+	}
+
+	end := time.Now().UTC()
+	if *to != "" {
+		if end, err = time.Parse("2006-01-02", *to); err != nil {
+			log.Fatalf("-to must be YYYY-MM-DD")
+		}
+	}
+	start := end.Add(-6 * *interval)
+	if *from != "" {
+		if start, err = time.Parse("2006-01-02", *from); err != nil {
+			log.Fatalf("-from must be YYYY-MM-DD")
+		}
+	}
+	if start.After(end) {
+		log.Fatalf("-from must not be after -to")
+	}
+	var asOf []time.Time
+	for at := start; !at.After(end); at = at.Add(*interval) {
+		asOf = append(asOf, at)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	db, err := config.InitDB()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer config.CloseDB(db)
+	db.Logger = db.Logger.LogMode(logger.Silent) // Keep stdout for the report
+
+	// The backtest reads blind indexes only, so no cache, audit or keys are needed
+	service := reputation.NewService(db, nil, nil, nil)
+	report, err := service.Backtest(context.Background(), tenantID, reputation.BacktestInput{
+		Candidate:   candidate,
+		AsOf:        asOf,
+		MaxSubjects: *maxSubjects,
+	})
+	if err != nil {
+		log.Fatalf("Backtest failed: %v", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		return
+	}
+	printReport(report)
+}
+
+// printReport writes a table per as-of date
+func printReport(report *reputation.BacktestReport) {
+	fmt.Printf("Baseline:  %s\nCandidate: %s\n", report.Baseline, report.Candidate)
+
+	for _, point := range report.Points {
+		fmt.Printf("\nAs of %s: %d subjects, mean |delta| %.2f, %d level changes\n",
+			point.AsOf.Format("2006-01-02"), point.Subjects, point.MeanAbsDelta, point.LevelChanges)
+		if point.BaselinePolicy != "" {
+			fmt.Printf("Production policy: %s\n", point.BaselinePolicy)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "\tMEAN\tP10\tMEDIAN\tP90\tLEVELS")
+		for _, row := range []struct {
+			name string
+			d    reputation.Distribution
+		}{{"baseline", point.Baseline}, {"candidate", point.Candidate}} {
+			fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%s\n", row.name, row.d.Mean, row.d.P10, row.d.Median, row.d.P90, formatCounts(row.d.Levels))
+		}
+		w.Flush()
+
+		if len(point.Transitions) > 0 {
+			fmt.Printf("Level changes: %s\n", formatCounts(point.Transitions))
+		}
+	}
+}
+
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", key, counts[key])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,77 @@
+package attestations
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages attestation HTTP endpoints
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new attestation handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Issue handles POST /v1/subjects/:id/attestations
+func (h *Handler) Issue(c *gin.Context) {
+	var input IssueInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	attestation, err := h.service.Issue(c.Request.Context(), tenantID, c.Param("id"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidClaims):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_claims",
+				"message": err.Error(),
+			})
+		case errors.Is(err, ErrPredicateNotSatisfied):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "predicate_not_satisfied",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "attestation_failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attestation)
+}
+
+// Verify handles POST /attestations/verify (public)
+func (h *Handler) Verify(c *gin.Context) {
+	var input VerifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.Verify(input))
+}
+
+// JWKS handles GET /.well-known/jwks.json (public)
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.JWKS())
+}
@@ -0,0 +1,408 @@
+package attestations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/reputation"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TokenType is the JWS "typ" of reputation attestations
+const TokenType = "sd+jwt"
+
+// CredentialType names the attestation format in the "vct" claim
+const CredentialType = "mighty-eagle/reputation-attestation/v1"
+
+// Claims a subject can disclose
+const (
+	ClaimReputationLevel   = "reputation_level"
+	ClaimLevelAtLeast      = "reputation_level_at_least"
+	ClaimBadgeCount        = "badge_count"
+	ClaimBadgeCountAtLeast = "badge_count_at_least"
+	ClaimVerified          = "verified"
+)
+
+const (
+	defaultTTL = 15 * time.Minute
+	minTTL     = time.Minute
+	maxTTL     = time.Hour
+
+	// clockSkew is tolerated between the issuer and relying parties
+	clockSkew = time.Minute
+)
+
+var (
+	// ErrInvalidClaims is returned for unknown or missing claims
+	ErrInvalidClaims = errors.New("invalid claims")
+	// ErrPredicateNotSatisfied is returned when a requested predicate is false;
+	// attestations never state a predicate that does not hold
+	ErrPredicateNotSatisfied = errors.New("predicate not satisfied")
+)
+
+// Service issues and verifies reputation attestations
+type Service struct {
+	db         *gorm.DB
+	audit      *audit.Logger
+	crypto     *encryption.Service
+	reputation *reputation.Service
+	signer     *signing.Signer
+	issuer     string
+}
+
+// NewService creates a new attestation service. The "iss" claim is taken
+// from ATTESTATION_ISSUER, falling back to API_BASE_URL.
+func NewService(db *gorm.DB, audit *audit.Logger, crypto *encryption.Service, reputation *reputation.Service, signer *signing.Signer) *Service {
+	issuer := os.Getenv("ATTESTATION_ISSUER")
+	if issuer == "" {
+		issuer = strings.TrimRight(os.Getenv("API_BASE_URL"), "/")
+	}
+	if issuer == "" {
+		issuer = "mighty-eagle"
+	}
+	return &Service{db: db, audit: audit, crypto: crypto, reputation: reputation, signer: signer, issuer: issuer}
+}
+
+// IssueInput selects the claims of an attestation. Exact values are listed
+// in Disclose; predicates state a lower bound without revealing the value.
+type IssueInput struct {
+	Audience          string   `json:"audience" binding:"required"` // Relying party the attestation is for
+	TTLSeconds        int      `json:"ttl_seconds"`
+	Disclose          []string `json:"disclose"` // reputation_level, badge_count, verified
+	LevelAtLeast      *string  `json:"reputation_level_at_least"`
+	BadgeCountAtLeast *int     `json:"badge_count_at_least"`
+}
+
+// Attestation is an issued SD-JWT with the claims it can disclose
+type Attestation struct {
+	ID        uuid.UUID              `json:"id"`
+	Token     string                 `json:"token"` // <jwt>~<disclosure>~...~
+	Audience  string                 `json:"audience"`
+	Claims    map[string]interface{} `json:"claims"`
+	IssuedAt  time.Time              `json:"issued_at"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// attestationClaims is the signed payload. Disclosable claims appear only as
+// digests in SD; the holder reveals each by presenting its disclosure.
+type attestationClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  string   `json:"aud"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+	ID        string   `json:"jti"`
+	Type      string   `json:"vct"`
+	SDAlg     string   `json:"_sd_alg"`
+	SD        []string `json:"_sd"`
+}
+
+// Issue signs an attestation about the subject's current reputation.
+// The token can be presented with any subset of its disclosures, so the
+// subject can reveal fewer claims than were issued.
+func (s *Service) Issue(ctx context.Context, tenantID uuid.UUID, subjectID string, input IssueInput) (*Attestation, error) {
+	ttl := defaultTTL
+	if input.TTLSeconds != 0 {
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+	}
+	if ttl < minTTL || ttl > maxTTL {
+		return nil, fmt.Errorf("%w: ttl_seconds must be between %d and %d", ErrInvalidClaims, int(minTTL.Seconds()), int(maxTTL.Seconds()))
+	}
+	disclose := make(map[string]bool)
+	for _, name := range input.Disclose {
+		if name != ClaimReputationLevel && name != ClaimBadgeCount && name != ClaimVerified {
+			return nil, fmt.Errorf("%w: '%s' cannot be disclosed", ErrInvalidClaims, name)
+		}
+		disclose[name] = true
+	}
+	if len(disclose) == 0 && input.LevelAtLeast == nil && input.BadgeCountAtLeast == nil {
+		return nil, fmt.Errorf("%w: at least one claim or predicate is required", ErrInvalidClaims)
+	}
+
+	claims := make(map[string]interface{})
+
+	if disclose[ClaimReputationLevel] || input.LevelAtLeast != nil {
+		result, err := s.reputation.GetReputation(ctx, tenantID, subjectID)
+		if err != nil {
+			return nil, err
+		}
+		if disclose[ClaimReputationLevel] {
+			claims[ClaimReputationLevel] = result.Level
+		}
+		if input.LevelAtLeast != nil {
+			if err := s.checkLevel(ctx, tenantID, result.Score, *input.LevelAtLeast); err != nil {
+				return nil, err
+			}
+			claims[ClaimLevelAtLeast] = *input.LevelAtLeast
+		}
+	}
+
+	if disclose[ClaimBadgeCount] || input.BadgeCountAtLeast != nil {
+		var badges int64
+		if err := s.db.WithContext(ctx).Model(&models.TrustBadge{}).
+			Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+			Count(&badges).Error; err != nil {
+			return nil, fmt.Errorf("failed to count badges: %w", err)
+		}
+		if disclose[ClaimBadgeCount] {
+			claims[ClaimBadgeCount] = badges
+		}
+		if input.BadgeCountAtLeast != nil {
+			if *input.BadgeCountAtLeast < 1 {
+				return nil, fmt.Errorf("%w: badge_count_at_least must be positive", ErrInvalidClaims)
+			}
+			if badges < int64(*input.BadgeCountAtLeast) {
+				return nil, fmt.Errorf("%w: subject holds fewer than %d badges", ErrPredicateNotSatisfied, *input.BadgeCountAtLeast)
+			}
+			claims[ClaimBadgeCountAtLeast] = *input.BadgeCountAtLeast
+		}
+	}
+
+	if disclose[ClaimVerified] {
+		var active int64
+		if err := s.db.WithContext(ctx).Model(&models.PersonaVerification{}).
+			Where("tenant_id = ? AND subject_index = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)",
+				tenantID, s.crypto.BlindIndex(tenantID, subjectID), "verified", time.Now()).
+			Count(&active).Error; err != nil {
+			return nil, fmt.Errorf("failed to check verification: %w", err)
+		}
+		claims[ClaimVerified] = active > 0
+	}
+
+	disclosures := make([]string, 0, len(claims))
+	digests := make([]string, 0, len(claims))
+	for _, name := range sortedNames(claims) {
+		disclosure, err := newDisclosure(name, claims[name])
+		if err != nil {
+			return nil, err
+		}
+		disclosures = append(disclosures, disclosure)
+		digests = append(digests, digest(disclosure))
+	}
+	// Digest order must not hint at which claims were issued
+	sort.Strings(digests)
+
+	now := time.Now().Truncate(time.Second)
+	id := uuid.New()
+	jwt, err := s.signer.SignJWT(TokenType, attestationClaims{
+		Issuer:    s.issuer,
+		Subject:   s.pairwiseSubject(tenantID, subjectID, input.Audience),
+		Audience:  input.Audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		ID:        id.String(),
+		Type:      CredentialType,
+		SDAlg:     "sha-256",
+		SD:        digests,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "attestation.issued",
+		SubjectID:    &subjectID,
+		ResourceType: stringPtr("attestation"),
+		ResourceID:   &id,
+		Metadata: map[string]interface{}{
+			"audience":   input.Audience,
+			"claims":     sortedNames(claims),
+			"expires_at": now.Add(ttl),
+		},
+	})
+
+	return &Attestation{
+		ID:        id,
+		Token:     jwt + "~" + strings.Join(disclosures, "~") + "~",
+		Audience:  input.Audience,
+		Claims:    claims,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// VerifyInput is a presented attestation
+type VerifyInput struct {
+	Token    string `json:"token" binding:"required"`
+	Audience string `json:"audience"` // Checked against "aud" when set
+}
+
+// VerifyResult describes a presented attestation. Only claims whose
+// disclosures were presented are listed.
+type VerifyResult struct {
+	Valid     bool                   `json:"valid"`
+	Reason    string                 `json:"reason,omitempty"`
+	Issuer    string                 `json:"issuer,omitempty"`
+	Subject   string                 `json:"subject,omitempty"`
+	Audience  string                 `json:"audience,omitempty"`
+	KeyID     string                 `json:"key_id,omitempty"`
+	IssuedAt  *time.Time             `json:"issued_at,omitempty"`
+	ExpiresAt *time.Time             `json:"expires_at,omitempty"`
+	Claims    map[string]interface{} `json:"claims,omitempty"`
+}
+
+// Verify checks an attestation's signature, lifetime, audience and
+// disclosures. Relying parties can do the same offline with the JWKS.
+func (s *Service) Verify(input VerifyInput) *VerifyResult {
+	parts := strings.Split(input.Token, "~")
+	header, payload, err := s.signer.VerifyJWT(parts[0])
+	if err != nil {
+		return &VerifyResult{Reason: err.Error()}
+	}
+	if header.Type != TokenType {
+		return &VerifyResult{Reason: "not a reputation attestation"}
+	}
+
+	var claims attestationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Type != CredentialType {
+		return &VerifyResult{Reason: "not a reputation attestation"}
+	}
+	issuedAt, expiresAt := time.Unix(claims.IssuedAt, 0).UTC(), time.Unix(claims.ExpiresAt, 0).UTC()
+	result := &VerifyResult{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		KeyID:     header.KeyID,
+		IssuedAt:  &issuedAt,
+		ExpiresAt: &expiresAt,
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != s.issuer:
+		result.Reason = "issued by another issuer"
+		return result
+	case now.After(expiresAt.Add(clockSkew)):
+		result.Reason = "attestation expired"
+		return result
+	case issuedAt.After(now.Add(clockSkew)):
+		result.Reason = "attestation issued in the future"
+		return result
+	case input.Audience != "" && input.Audience != claims.Audience:
+		result.Reason = "attestation is for another audience"
+		return result
+	}
+
+	issued := make(map[string]bool, len(claims.SD))
+	for _, d := range claims.SD {
+		issued[d] = true
+	}
+	result.Claims = make(map[string]interface{})
+	for _, disclosure := range parts[1:] {
+		if disclosure == "" {
+			continue
+		}
+		if !issued[digest(disclosure)] {
+			result.Reason = "disclosure was not issued with this attestation"
+			result.Claims = nil
+			return result
+		}
+
+		name, value, err := openDisclosure(disclosure)
+		if err != nil {
+			result.Reason = err.Error()
+			result.Claims = nil
+			return result
+		}
+		if _, dup := result.Claims[name]; dup {
+			result.Reason = "claim disclosed twice"
+			result.Claims = nil
+			return result
+		}
+		result.Claims[name] = value
+	}
+
+	result.Valid = true
+	return result
+}
+
+// JWKS returns the published verification keys
+func (s *Service) JWKS() signing.JWKSet {
+	return s.signer.JWKS()
+}
+
+// checkLevel checks a score against the lower bound of a level band
+func (s *Service) checkLevel(ctx context.Context, tenantID uuid.UUID, score float64, level string) error {
+	bands, err := s.reputation.ActiveLevelBands(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, band := range bands {
+		if band.Level == level {
+			if score < band.MinScore {
+				return fmt.Errorf("%w: reputation is below %s", ErrPredicateNotSatisfied, level)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown level '%s'", ErrInvalidClaims, level)
+}
+
+// pairwiseSubject derives a subject identifier that is stable for one
+// audience but cannot be correlated across audiences or to the subject ID
+func (s *Service) pairwiseSubject(tenantID uuid.UUID, subjectID, audience string) string {
+	return s.crypto.BlindIndex(tenantID, "attestation\x00"+audience+"\x00"+subjectID)
+}
+
+// newDisclosure encodes a salted [salt, name, value] disclosure
+func newDisclosure(name string, value interface{}) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	encoded, err := json.Marshal([]interface{}{base64.RawURLEncoding.EncodeToString(salt), name, value})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode disclosure: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// openDisclosure decodes a disclosure into its claim name and value
+func openDisclosure(disclosure string) (string, interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(disclosure)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed disclosure")
+	}
+	var parts []interface{}
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) != 3 {
+		return "", nil, fmt.Errorf("malformed disclosure")
+	}
+	name, ok := parts[1].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("malformed disclosure")
+	}
+	return name, parts[2], nil
+}
+
+func digest(disclosure string) string {
+	sum := sha256.Sum256([]byte(disclosure))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func sortedNames(claims map[string]interface{}) []string {
+	names := make([]string, 0, len(claims))
+	for name := range claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
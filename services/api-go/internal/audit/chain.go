@@ -0,0 +1,310 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GenesisHash is the prev_hash of the first event in a tenant's chain
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+const (
+	// MaxVerifyRange caps the events one verification walks
+	MaxVerifyRange = 100000
+
+	// verifyBatchSize is the number of events loaded per query while verifying
+	verifyBatchSize = 1000
+)
+
+// ErrInvalidRange is returned for a verification range outside the chain
+var ErrInvalidRange = errors.New("invalid range")
+
+// chainLink is the canonical content an event's hash covers. Field order is
+// fixed by the struct, so the JSON encoding is stable.
+type chainLink struct {
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	Sequence      int64      `json:"sequence"`
+	PrevHash      string     `json:"prev_hash"`
+	ID            uuid.UUID  `json:"id"`
+	EventType     string     `json:"event_type"`
+	EventVersion  string     `json:"event_version"`
+	ActorID       *string    `json:"actor_id"`
+	ResourceType  *string    `json:"resource_type"`
+	ResourceID    *uuid.UUID `json:"resource_id"`
+	CreatedAt     string     `json:"created_at"` // RFC 3339 in UTC with microseconds, as stored
+	ContentDigest string     `json:"content_digest"`
+}
+
+// ChainHash computes the hash of a chained event
+func ChainHash(event *models.EventLog) string {
+	hash := sha256.Sum256(ChainLink(event))
+	return hex.EncodeToString(hash[:])
+}
+
+// ChainLink returns the canonical content an event's hash is taken over
+func ChainLink(event *models.EventLog) []byte {
+	link := chainLink{
+		TenantID:      event.TenantID,
+		Sequence:      derefInt64(event.Sequence),
+		PrevHash:      derefString(event.PrevHash),
+		ID:            event.ID,
+		EventType:     event.EventType,
+		EventVersion:  event.EventVersion,
+		ActorID:       event.ActorID,
+		ResourceType:  event.ResourceType,
+		ResourceID:    event.ResourceID,
+		CreatedAt:     event.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		ContentDigest: derefString(event.ContentDigest),
+	}
+	content, _ := json.Marshal(link)
+	return content
+}
+
+// contentDigest is a keyed digest of an event's plaintext personal fields.
+// Keying it means the digest left in the chain after an erasure cannot be
+// used to guess the erased values.
+func (l *Logger) contentDigest(tenantID uuid.UUID, subjectID *string, metadata string, ipAddress, userAgent *string) string {
+	content, _ := json.Marshal(struct {
+		SubjectID *string         `json:"subject_id"`
+		Metadata  json.RawMessage `json:"metadata"`
+		IPAddress *string         `json:"ip_address"`
+		UserAgent *string         `json:"user_agent"`
+	}{subjectID, canonicalJSON(metadata), ipAddress, userAgent})
+	return l.crypto.BlindIndex(tenantID, string(content))
+}
+
+// appendChained inserts an event as the next link of its tenant's chain. The
+// chain head row is locked for the transaction, so appends per tenant are
+// serialized while different tenants proceed in parallel.
+func (l *Logger) appendChained(ctx context.Context, event *models.EventLog) error {
+	return l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head := models.EventLogChainHead{TenantID: event.TenantID, Hash: GenesisHash}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&head).Error; err != nil {
+			return fmt.Errorf("failed to initialise chain head: %w", err)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("tenant_id = ?", event.TenantID).
+			First(&head).Error; err != nil {
+			return fmt.Errorf("failed to lock chain head: %w", err)
+		}
+
+		sequence := head.Sequence + 1
+		prevHash := head.Hash
+		event.Sequence = &sequence
+		event.PrevHash = &prevHash
+		hash := ChainHash(event)
+		event.Hash = &hash
+
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.EventLogChainHead{}).Where("tenant_id = ?", event.TenantID).Updates(map[string]interface{}{
+			"sequence":   sequence,
+			"hash":       hash,
+			"updated_at": time.Now(),
+		}).Error
+	})
+}
+
+// VerifyChainInput bounds a chain verification by sequence number
+type VerifyChainInput struct {
+	FromSequence int64 // Defaults to 1
+	ToSequence   int64 // Defaults to the chain head; at most MaxVerifyRange events are walked
+}
+
+// ChainBreak is the first point at which a chain fails to verify
+type ChainBreak struct {
+	Sequence int64      `json:"sequence"`
+	EventID  *uuid.UUID `json:"event_id,omitempty"`
+	Reason   string     `json:"reason"` // missing_event, prev_hash_mismatch, hash_mismatch, content_mismatch, head_mismatch
+	Expected string     `json:"expected,omitempty"`
+	Actual   string     `json:"actual,omitempty"`
+}
+
+// ChainVerification reports the result of walking part of a tenant's chain
+type ChainVerification struct {
+	Valid          bool        `json:"valid"`
+	FromSequence   int64       `json:"from_sequence"`
+	ToSequence     int64       `json:"to_sequence"`
+	HeadSequence   int64       `json:"head_sequence"`
+	HeadHash       string      `json:"head_hash"`
+	EventsChecked  int         `json:"events_checked"`
+	RedactedEvents int         `json:"redacted_events"` // Erased personal fields; linkage verified, content not
+	Break          *ChainBreak `json:"break,omitempty"`
+	VerifiedAt     time.Time   `json:"verified_at"`
+}
+
+// VerifyChain walks a range of the tenant's chain and reports the first
+// break. Each event must follow its predecessor, hash to its stored hash and,
+// unless an erasure tombstone records its redaction, still match its content
+// digest. A range ending at the head must also end on the head's hash, which
+// catches events cut from the end.
+func (l *Logger) VerifyChain(ctx context.Context, tenantID uuid.UUID, input VerifyChainInput) (*ChainVerification, error) {
+	var head models.EventLogChainHead
+	if err := l.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&head).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load chain head: %w", err)
+		}
+		head = models.EventLogChainHead{TenantID: tenantID, Hash: GenesisHash}
+	}
+
+	from, to := input.FromSequence, input.ToSequence
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 {
+		to = head.Sequence
+	}
+	if from < 1 || to > head.Sequence || (from > to && head.Sequence > 0) {
+		return nil, fmt.Errorf("%w: sequences must lie within 1..%d", ErrInvalidRange, head.Sequence)
+	}
+	if to-from+1 > MaxVerifyRange {
+		to = from + MaxVerifyRange - 1
+	}
+
+	result := &ChainVerification{
+		Valid:        true,
+		FromSequence: from,
+		ToSequence:   to,
+		HeadSequence: head.Sequence,
+		HeadHash:     head.Hash,
+		VerifiedAt:   time.Now(),
+	}
+	if head.Sequence == 0 {
+		result.FromSequence, result.ToSequence = 0, 0
+		return result, nil
+	}
+
+	expectedPrev := GenesisHash
+	if from > 1 {
+		var prev models.EventLog
+		if err := l.db.WithContext(ctx).Where("tenant_id = ? AND sequence = ?", tenantID, from-1).First(&prev).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				result.fail(ChainBreak{Sequence: from - 1, Reason: "missing_event"})
+				return result, nil
+			}
+			return nil, fmt.Errorf("failed to load event %d: %w", from-1, err)
+		}
+		expectedPrev = derefString(prev.Hash)
+	}
+
+	next := from
+	for next <= to {
+		var batch []models.EventLog
+		if err := l.db.WithContext(ctx).Where("tenant_id = ? AND sequence >= ? AND sequence <= ?", tenantID, next, to).
+			Order("sequence ASC").Limit(verifyBatchSize).Find(&batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to load events: %w", err)
+		}
+		if len(batch) == 0 {
+			result.fail(ChainBreak{Sequence: next, Reason: "missing_event"})
+			return result, nil
+		}
+
+		redacted, err := l.redactedEvents(ctx, tenantID, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range batch {
+			event := &batch[i]
+			eventID := event.ID
+			if *event.Sequence != next {
+				result.fail(ChainBreak{Sequence: next, Reason: "missing_event"})
+				return result, nil
+			}
+			if derefString(event.PrevHash) != expectedPrev {
+				result.fail(ChainBreak{Sequence: next, EventID: &eventID, Reason: "prev_hash_mismatch", Expected: expectedPrev, Actual: derefString(event.PrevHash)})
+				return result, nil
+			}
+			if hash := ChainHash(event); hash != derefString(event.Hash) {
+				result.fail(ChainBreak{Sequence: next, EventID: &eventID, Reason: "hash_mismatch", Expected: hash, Actual: derefString(event.Hash)})
+				return result, nil
+			}
+
+			if redacted[event.ID] {
+				result.RedactedEvents++
+			} else {
+				if err := l.DecryptEvent(ctx, event); err != nil {
+					return nil, err
+				}
+				digest := l.contentDigest(tenantID, event.SubjectID, event.Metadata, event.IPAddress, event.UserAgent)
+				if digest != derefString(event.ContentDigest) {
+					result.fail(ChainBreak{Sequence: next, EventID: &eventID, Reason: "content_mismatch"})
+					return result, nil
+				}
+			}
+
+			expectedPrev = derefString(event.Hash)
+			result.EventsChecked++
+			next++
+		}
+	}
+
+	if to == head.Sequence && expectedPrev != head.Hash {
+		result.fail(ChainBreak{Sequence: to, Reason: "head_mismatch", Expected: head.Hash, Actual: expectedPrev})
+	}
+	return result, nil
+}
+
+// redactedEvents returns which events of a batch a subject erasure redacted
+func (l *Logger) redactedEvents(ctx context.Context, tenantID uuid.UUID, batch []models.EventLog) (map[uuid.UUID]bool, error) {
+	ids := make([]uuid.UUID, len(batch))
+	for i, event := range batch {
+		ids[i] = event.ID
+	}
+
+	var redactedIDs []uuid.UUID
+	if err := l.db.WithContext(ctx).Model(&models.ErasureTombstone{}).
+		Where("tenant_id = ? AND source_table = ? AND record_id IN ?", tenantID, "event_log", ids).
+		Pluck("record_id", &redactedIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load erasure tombstones: %w", err)
+	}
+
+	redacted := make(map[uuid.UUID]bool, len(redactedIDs))
+	for _, id := range redactedIDs {
+		redacted[id] = true
+	}
+	return redacted, nil
+}
+
+func (v *ChainVerification) fail(b ChainBreak) {
+	v.Valid = false
+	v.Break = &b
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys and no
+// insignificant whitespace; numbers keep their original digits
+func canonicalJSON(document string) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage(`null`)
+	}
+	canonical, _ := json.Marshal(value)
+	return canonical
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt64(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
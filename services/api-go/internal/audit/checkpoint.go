@@ -0,0 +1,292 @@
+package audit
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// checkpointInterval is how often tenants with new events are checkpointed
+	checkpointInterval = time.Hour
+
+	// MaxCheckpointEvents caps the events one checkpoint covers; a larger
+	// backlog is split over consecutive checkpoints
+	MaxCheckpointEvents = 100000
+)
+
+// ErrNotCheckpointed is returned for an event no checkpoint covers yet
+var ErrNotCheckpointed = errors.New("event not checkpointed")
+
+// Checkpointer commits tenants' audit events to signed Merkle checkpoints
+type Checkpointer struct {
+	db     *gorm.DB
+	signer *signing.Signer
+}
+
+// NewCheckpointer creates a new checkpointer
+func NewCheckpointer(db *gorm.DB, signer *signing.Signer) *Checkpointer {
+	return &Checkpointer{db: db, signer: signer}
+}
+
+// CheckpointStatement is the document signed for a checkpoint
+type CheckpointStatement struct {
+	Type         string     `json:"type"`
+	CheckpointID uuid.UUID  `json:"checkpoint_id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	FromSequence int64      `json:"from_sequence"`
+	ToSequence   int64      `json:"to_sequence"`
+	TreeSize     int64      `json:"tree_size"`
+	MerkleRoot   string     `json:"merkle_root"`
+	ChainHash    string     `json:"chain_hash"` // Ties the tree to the hash chain
+	PreviousID   *uuid.UUID `json:"previous_id"`
+	IssuedAt     time.Time  `json:"issued_at"`
+}
+
+// InclusionProof shows that an event is a leaf of a signed checkpoint. The
+// verifier hashes chain_link to get event_hash, hashes that as a leaf, folds
+// in audit_path per RFC 9162 section 2.1.3.2 and compares the result with
+// the checkpoint's signed merkle_root.
+type InclusionProof struct {
+	EventID    uuid.UUID              `json:"event_id"`
+	Sequence   int64                  `json:"sequence"`
+	ChainLink  json.RawMessage        `json:"chain_link"`
+	EventHash  string                 `json:"event_hash"`
+	LeafIndex  int64                  `json:"leaf_index"` // Sequence - checkpoint from_sequence
+	TreeSize   int64                  `json:"tree_size"`
+	AuditPath  []string               `json:"audit_path"` // Sibling hashes from the leaf up
+	Checkpoint models.AuditCheckpoint `json:"checkpoint"`
+}
+
+// Worker checkpoints every tenant with events since its last checkpoint
+func (c *Checkpointer) Worker(ctx context.Context) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var tenantIDs []uuid.UUID
+			if err := c.db.WithContext(ctx).Raw(`
+				SELECT h.tenant_id
+				FROM event_log_chain_heads h
+				WHERE h.sequence > COALESCE((SELECT MAX(to_sequence) FROM audit_checkpoints c WHERE c.tenant_id = h.tenant_id), 0)`).
+				Scan(&tenantIDs).Error; err != nil {
+				log.Printf("Error listing tenants to checkpoint: %v", err)
+				continue
+			}
+			for _, tenantID := range tenantIDs {
+				if _, err := c.CheckpointTenant(ctx, tenantID); err != nil {
+					log.Printf("Audit checkpoint failed for tenant %s: %v", tenantID, err)
+				}
+			}
+		}
+	}
+}
+
+// CheckpointTenant covers the tenant's events up to the current chain head
+// with one or more checkpoints and returns the ones created
+func (c *Checkpointer) CheckpointTenant(ctx context.Context, tenantID uuid.UUID) ([]models.AuditCheckpoint, error) {
+	var head models.EventLogChainHead
+	if err := c.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&head).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load chain head: %w", err)
+	}
+
+	var created []models.AuditCheckpoint
+	for {
+		var previous *models.AuditCheckpoint
+		var latest models.AuditCheckpoint
+		err := c.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("to_sequence DESC").First(&latest).Error
+		switch {
+		case err == nil:
+			previous = &latest
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return created, fmt.Errorf("failed to load latest checkpoint: %w", err)
+		}
+
+		from := int64(1)
+		if previous != nil {
+			from = previous.ToSequence + 1
+		}
+		if from > head.Sequence {
+			return created, nil
+		}
+		to := head.Sequence
+		if to-from+1 > MaxCheckpointEvents {
+			to = from + MaxCheckpointEvents - 1
+		}
+
+		checkpoint, err := c.createCheckpoint(ctx, tenantID, previous, from, to)
+		if err != nil {
+			return created, err
+		}
+		if checkpoint == nil {
+			return created, nil // Another instance checkpointed this range
+		}
+		created = append(created, *checkpoint)
+	}
+}
+
+// createCheckpoint signs the Merkle root over events from..to
+func (c *Checkpointer) createCheckpoint(ctx context.Context, tenantID uuid.UUID, previous *models.AuditCheckpoint, from, to int64) (*models.AuditCheckpoint, error) {
+	eventHashes, err := c.eventHashes(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([][]byte, len(eventHashes))
+	for i, eventHash := range eventHashes {
+		leaves[i] = MerkleLeafHash(eventHash)
+	}
+
+	statement := CheckpointStatement{
+		Type:         "audit_checkpoint",
+		CheckpointID: uuid.New(),
+		TenantID:     tenantID,
+		FromSequence: from,
+		ToSequence:   to,
+		TreeSize:     to - from + 1,
+		MerkleRoot:   hex.EncodeToString(MerkleRoot(leaves)),
+		ChainHash:    hex.EncodeToString(eventHashes[len(eventHashes)-1]),
+		IssuedAt:     time.Now().UTC(),
+	}
+	if previous != nil {
+		statement.PreviousID = &previous.ID
+	}
+	payload, signature, err := c.signer.SignJSON(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+	signatureJSON, err := json.Marshal(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signature: %w", err)
+	}
+
+	checkpoint := models.AuditCheckpoint{
+		ID:           statement.CheckpointID,
+		TenantID:     tenantID,
+		FromSequence: from,
+		ToSequence:   to,
+		TreeSize:     statement.TreeSize,
+		MerkleRoot:   statement.MerkleRoot,
+		ChainHash:    statement.ChainHash,
+		PreviousID:   statement.PreviousID,
+		Statement:    string(payload),
+		Signature:    string(signatureJSON),
+		CreatedAt:    statement.IssuedAt,
+	}
+	result := c.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&checkpoint)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to record checkpoint: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+// eventHashes loads the chain hashes of events from..to, failing on gaps
+func (c *Checkpointer) eventHashes(ctx context.Context, tenantID uuid.UUID, from, to int64) ([][]byte, error) {
+	var hashes []string
+	if err := c.db.WithContext(ctx).Model(&models.EventLog{}).
+		Where("tenant_id = ? AND sequence >= ? AND sequence <= ?", tenantID, from, to).
+		Order("sequence ASC").Pluck("hash", &hashes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load event hashes: %w", err)
+	}
+	if int64(len(hashes)) != to-from+1 {
+		return nil, fmt.Errorf("chain has %d of %d events between %d and %d", len(hashes), to-from+1, from, to)
+	}
+
+	decoded := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		raw, err := hex.DecodeString(hash)
+		if err != nil {
+			return nil, fmt.Errorf("event %d has a malformed hash", from+int64(i))
+		}
+		decoded[i] = raw
+	}
+	return decoded, nil
+}
+
+// ListCheckpoints returns the tenant's checkpoints, newest first
+func (c *Checkpointer) ListCheckpoints(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AuditCheckpoint, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	query := c.db.WithContext(ctx).Model(&models.AuditCheckpoint{}).Where("tenant_id = ?", tenantID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count checkpoints: %w", err)
+	}
+
+	var checkpoints []models.AuditCheckpoint
+	if err := query.Order("to_sequence DESC").Limit(limit).Offset(offset).Find(&checkpoints).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	return checkpoints, total, nil
+}
+
+// GetCheckpoint retrieves a checkpoint
+func (c *Checkpointer) GetCheckpoint(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	if err := c.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", id, tenantID).First(&checkpoint).Error; err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// Proof builds the inclusion proof of an event in the checkpoint covering it
+func (c *Checkpointer) Proof(ctx context.Context, tenantID uuid.UUID, eventID uuid.UUID) (*InclusionProof, error) {
+	var event models.EventLog
+	if err := c.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", eventID, tenantID).First(&event).Error; err != nil {
+		return nil, err
+	}
+	if event.Sequence == nil {
+		return nil, fmt.Errorf("%w: event was logged before chaining", ErrNotCheckpointed)
+	}
+
+	var checkpoint models.AuditCheckpoint
+	if err := c.db.WithContext(ctx).Where("tenant_id = ? AND from_sequence <= ? AND to_sequence >= ?", tenantID, *event.Sequence, *event.Sequence).
+		First(&checkpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: the next checkpoint will cover it", ErrNotCheckpointed)
+		}
+		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	eventHashes, err := c.eventHashes(ctx, tenantID, checkpoint.FromSequence, checkpoint.ToSequence)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([][]byte, len(eventHashes))
+	for i, eventHash := range eventHashes {
+		leaves[i] = MerkleLeafHash(eventHash)
+	}
+	index := *event.Sequence - checkpoint.FromSequence
+
+	return &InclusionProof{
+		EventID:    event.ID,
+		Sequence:   *event.Sequence,
+		ChainLink:  ChainLink(&event),
+		EventHash:  derefString(event.Hash),
+		LeafIndex:  index,
+		TreeSize:   checkpoint.TreeSize,
+		AuditPath:  hexHashes(MerkleAuditPath(int(index), leaves)),
+		Checkpoint: checkpoint,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// Export key types
+const (
+	ExportKeyAge     = "age"
+	ExportKeyOpenPGP = "openpgp"
+)
+
+// exportKeyExtensions are the file extensions of encrypted exports
+var exportKeyExtensions = map[string]string{
+	ExportKeyAge:     "age",
+	ExportKeyOpenPGP: "gpg",
+}
+
+// ErrInvalidExportKey is returned for a public key exports cannot be encrypted to
+var ErrInvalidExportKey = errors.New("invalid export key")
+
+// exportRecipient encrypts export files to a tenant's public key
+type exportRecipient interface {
+	// Encrypt starts an encrypted file on w; closing the writer finishes it
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+	Fingerprint() string
+}
+
+// parseExportKey parses a public key of the given type
+func parseExportKey(keyType, publicKey string) (exportRecipient, error) {
+	publicKey = strings.TrimSpace(publicKey)
+	switch keyType {
+	case ExportKeyAge:
+		return parseAgeRecipient(publicKey)
+	case ExportKeyOpenPGP:
+		return parseOpenPGPRecipient(publicKey)
+	default:
+		return nil, fmt.Errorf("%w: key type must be %s or %s", ErrInvalidExportKey, ExportKeyAge, ExportKeyOpenPGP)
+	}
+}
+
+// ageRecipient is an age X25519 recipient, "age1..."
+type ageRecipient struct {
+	recipient *age.X25519Recipient
+}
+
+func parseAgeRecipient(encoded string) (*ageRecipient, error) {
+	recipient, err := age.ParseX25519Recipient(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not an age X25519 recipient (age1...)", ErrInvalidExportKey)
+	}
+	return &ageRecipient{recipient: recipient}, nil
+}
+
+func (r *ageRecipient) Fingerprint() string { return r.recipient.String() }
+
+func (r *ageRecipient) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return age.Encrypt(w, r.recipient)
+}
+
+// openpgpRecipient is an armored OpenPGP public key
+type openpgpRecipient struct {
+	entity *openpgp.Entity
+}
+
+// parseOpenPGPRecipient accepts a single armored public key with an
+// encryption subkey, RSA or elliptic curve as GnuPG generates by default
+func parseOpenPGPRecipient(armored string) (*openpgpRecipient, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExportKey, err)
+	}
+	if len(entities) != 1 {
+		return nil, fmt.Errorf("%w: expected one public key, got %d", ErrInvalidExportKey, len(entities))
+	}
+	recipient := &openpgpRecipient{entity: entities[0]}
+
+	// Fail now rather than on the first export if the key cannot encrypt
+	probe, err := recipient.Encrypt(io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExportKey, err)
+	}
+	probe.Close()
+	return recipient, nil
+}
+
+func (r *openpgpRecipient) Fingerprint() string {
+	return fmt.Sprintf("%X", r.entity.PrimaryKey.Fingerprint)
+}
+
+func (r *openpgpRecipient) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return openpgp.Encrypt(w, openpgp.EntityList{r.entity}, nil, &openpgp.FileHints{IsBinary: true}, nil)
+}
@@ -1,61 +1,124 @@
 package audit
 
 import (
-	"bytes"
+	"bufio"
+	"compress/gzip"
 	"context"
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/storage"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// Exporter manages audit export jobs
+// gzipThreshold is the export size above which files are stored gzipped
+const gzipThreshold = 1 << 20
+
+var (
+	// ErrUnsupportedFormat is returned for an export format that is not registered
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+
+	// ErrInvalidEventType is returned for an event type filter that is not an event type name
+	ErrInvalidEventType = errors.New("invalid event type")
+)
+
+// Exporter manages audit export jobs. Jobs are queued in Postgres and run by
+// the Worker of any replica.
 type Exporter struct {
-	db      *gorm.DB
-	logger  *Logger
-	billing BillingService
+	db          *gorm.DB
+	logger      *Logger
+	billing     BillingService
+	blobs       storage.BlobStore
+	urls        *signing.URLSigner
+	signer      *signing.Signer
+	downloadTTL time.Duration
+	workerID    string        // Identifies this replica's leases
+	concurrency int           // Jobs run at once by this replica
+	wake        chan struct{} // Signals a newly queued job
 }
 
 type BillingService interface {
 	ReportUsage(ctx context.Context, tenantID uuid.UUID, metric string, amount int) error
 }
 
-// NewExporter creates a new exporter service
-func NewExporter(db *gorm.DB, logger *Logger, billing BillingService) *Exporter {
-	return &Exporter{db: db, logger: logger, billing: billing}
+// NewExporter creates a new exporter service. Completed exports can be
+// downloaded for EXPORT_DOWNLOAD_EXPIRY_HOURS, 24 by default; each replica
+// runs EXPORT_WORKER_CONCURRENCY jobs at once, 2 by default.
+func NewExporter(db *gorm.DB, logger *Logger, billing BillingService, blobs storage.BlobStore, urls *signing.URLSigner, signer *signing.Signer) *Exporter {
+	downloadTTL := 24 * time.Hour
+	if hours, err := strconv.Atoi(os.Getenv("EXPORT_DOWNLOAD_EXPIRY_HOURS")); err == nil && hours > 0 {
+		downloadTTL = time.Duration(hours) * time.Hour
+	}
+	concurrency := 2
+	if n, err := strconv.Atoi(os.Getenv("EXPORT_WORKER_CONCURRENCY")); err == nil && n > 0 {
+		concurrency = n
+	}
+	hostname, _ := os.Hostname()
+	return &Exporter{
+		db:          db,
+		logger:      logger,
+		billing:     billing,
+		blobs:       blobs,
+		urls:        urls,
+		signer:      signer,
+		downloadTTL: downloadTTL,
+		workerID:    hostname + "/" + uuid.NewString()[:8],
+		concurrency: concurrency,
+		wake:        make(chan struct{}, 1),
+	}
 }
 
-// CreateExportJob creates a new export job and starts processing
-func (e *Exporter) CreateExportJob(ctx context.Context, tenantID uuid.UUID, format string, startDate, endDate time.Time) (*models.AuditExportJob, error) {
-	if format != "csv" && format != "json" {
-		return nil, fmt.Errorf("unsupported format: %s", format)
+// CreateExportJob queues a new export job. When eventTypes is not empty only
+// events of those types are exported.
+func (e *Exporter) CreateExportJob(ctx context.Context, tenantID uuid.UUID, format string, eventTypes []string, startDate, endDate time.Time) (*models.AuditExportJob, error) {
+	if _, ok := LookupExportFormat(format); !ok {
+		return nil, fmt.Errorf("%w: %s; supported formats are %s", ErrUnsupportedFormat, format, strings.Join(ExportFormatNames(), ", "))
+	}
+	for _, eventType := range eventTypes {
+		if !validEventType(eventType) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEventType, eventType)
+		}
 	}
 
-	expiresAt := time.Now().Add(24 * time.Hour)
 	job := models.AuditExportJob{
-		TenantID:  tenantID,
-		Format:    format,
-		Status:    "pending",
-		StartDate: startDate,
-		EndDate:   endDate,
-		CreatedAt: time.Now(),
-		ExpiresAt: &expiresAt, // Download valid for 24h
+		TenantID:    tenantID,
+		Format:      format,
+		Status:      "pending",
+		StartDate:   startDate,
+		EndDate:     endDate,
+		MaxAttempts: exportMaxAttempts,
+		RunAfter:    time.Now(),
+		CreatedAt:   time.Now(),
 		Parameters: string(convertMapToJSON(map[string]interface{}{
-			"start_date": startDate,
-			"end_date":   endDate,
+			"start_date":  startDate,
+			"end_date":    endDate,
+			"event_types": eventTypes,
 		})),
 	}
+	if len(eventTypes) > 0 {
+		filter := toPostgresArray(eventTypes)
+		job.EventTypes = &filter
+	}
 
 	if err := e.db.Create(&job).Error; err != nil {
 		return nil, err
 	}
 
-	// Start Async Processing
-	go e.processJob(job.ID, tenantID, format, startDate, endDate)
+	// Let an idle local runner pick the job up without waiting for its next poll
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
 
 	// Report Usage
 	go func() {
@@ -73,94 +136,324 @@ func (e *Exporter) GetExportJob(ctx context.Context, tenantID uuid.UUID, jobID u
 	if err := e.db.Where("id = ? AND tenant_id = ?", jobID, tenantID).First(&job).Error; err != nil {
 		return nil, err
 	}
+
+	if job.Status == "completed" && job.StorageKey != nil && job.ExpiresAt != nil && job.ExpiresAt.After(time.Now()) {
+		url := e.urls.Sign(exportDownloadPath(job.ID), *job.ExpiresAt)
+		job.DownloadURL = &url
+	}
 	return &job, nil
 }
 
-func (e *Exporter) processJob(jobID, tenantID uuid.UUID, format string, startDate, endDate time.Time) {
-	// Simple in-memory processing for MVP.
-	
-	ctx := context.Background()
+// exportResult describes a stored export file
+type exportResult struct {
+	key               string
+	compression       string
+	encryption        *ManifestKey
+	size              int64
+	count             int
+	sha256            string
+	manifest          string
+	manifestSignature string
+}
+
+// buildExport writes the job's events to a temporary file, compresses and
+// encrypts it as configured, stores it and signs its manifest. Written
+// events are counted in progress.
+func (e *Exporter) buildExport(ctx context.Context, job *models.AuditExportJob, progress *exportProgress) (*exportResult, error) {
+	format, ok := LookupExportFormat(job.Format)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, job.Format)
+	}
 
-	// 1. Fetch Logs
-	startStr := startDate.Format(time.RFC3339)
-	endStr := endDate.Format(time.RFC3339)
-	
-	logs, _, err := e.logger.GetEventLog(ctx, GetEventLogInput{
-		TenantID:  tenantID,
-		StartDate: &startStr,
-		EndDate:   &endStr,
-		Limit:     10000,
-	})
+	total, err := e.countEvents(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	progress.total.Store(total)
 
+	// 1. Stream events into a temporary file, never holding them all in memory
+	file, err := os.CreateTemp("", "audit-export-*")
 	if err != nil {
-		e.failJob(jobID, err.Error())
-		return
+		return nil, fmt.Errorf("failed to create export file: %w", err)
 	}
+	defer os.Remove(file.Name())
+	defer file.Close()
 
-	// 2. Generate Content
-	var content []byte
-	if format == "csv" {
-		content, err = e.generateCSV(logs)
-	} else {
-		content, err = json.MarshalIndent(logs, "", "  ")
+	count, err := e.writeEvents(ctx, file, job, format, progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
 
+	// 2. Gzip large exports, unless the format compresses itself
+	upload, compression := file, ""
+	size, err := file.Seek(0, io.SeekEnd)
 	if err != nil {
-		e.failJob(jobID, fmt.Sprintf("failed to generate content: %v", err))
-		return
-	}
-
-	// 3. Store result as Data URI (MVP Hack)
-	dataURI := fmt.Sprintf("data:text/%s;charset=utf-8;base64,%s", format, content) 
-	
-	e.completeJob(jobID, dataURI, int64(len(content)), len(logs))
-}
-
-func (e *Exporter) generateCSV(logs []models.EventLog) ([]byte, error) {
-	b := &bytes.Buffer{}
-	w := csv.NewWriter(b)
-	
-	// Header
-	w.Write([]string{"ID", "Time", "Event", "Subject", "Resource", "Metadata"})
-	
-	for _, l := range logs {
-		subj := ""
-		if l.SubjectID != nil {
-			subj = *l.SubjectID
+		return nil, err
+	}
+	if format.Compressible && size > gzipThreshold {
+		compressed, err := gzipFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress export: %w", err)
 		}
-		res := ""
-		if l.ResourceID != nil {
-			res = (*l.ResourceID).String()
+		defer os.Remove(compressed.Name())
+		defer compressed.Close()
+		if size, err = compressed.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
 		}
-		
-		w.Write([]string{
-			l.ID.String(),
-			l.CreatedAt.Format(time.RFC3339),
-			l.EventType,
-			subj,
-			res,
-			l.Metadata,
-		})
-	}
-	w.Flush()
-	return b.Bytes(), nil
-}
-
-func (e *Exporter) failJob(id uuid.UUID, reason string) {
-	e.db.Model(&models.AuditExportJob{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":        "failed",
-		"error_message": reason,
-	})
-}
-
-func (e *Exporter) completeJob(id uuid.UUID, url string, size int64, count int) {
-	e.db.Model(&models.AuditExportJob{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":           "completed",
-		"download_url":     url,
-		"file_size_bytes":  size,
-		"record_count":     count,
-		"completed_at":     time.Now(),
-	})
+		upload, compression = compressed, "gzip"
+	}
+
+	// 3. Encrypt to the tenant's key, if it registered one
+	result := &exportResult{compression: compression, count: count}
+	exportKeyRecord, recipient, err := e.exportRecipientFor(ctx, job.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient != nil {
+		encrypted, err := encryptFile(upload, recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt export: %w", err)
+		}
+		defer os.Remove(encrypted.Name())
+		defer encrypted.Close()
+		if size, err = encrypted.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		upload = encrypted
+		result.encryption = &ManifestKey{KeyType: exportKeyRecord.KeyType, Fingerprint: recipient.Fingerprint()}
+	}
+	result.size = size
+	if result.sha256, err = sha256File(upload); err != nil {
+		return nil, err
+	}
+
+	// 4. Store the file; downloads are served through signed links
+	encryption := ""
+	if result.encryption != nil {
+		encryption = result.encryption.KeyType
+	}
+	result.key = exportKey(job.TenantID, job.ID, format.Extension, compression, encryption)
+	if err := e.blobs.Put(ctx, result.key, upload, size, exportContentType(job.Format, compression, encryption)); err != nil {
+		return nil, err
+	}
+
+	// 5. Sign a manifest over the stored file
+	if result.manifest, result.manifestSignature, err = e.signManifest(ctx, job, result); err != nil {
+		e.blobs.Delete(context.Background(), result.key)
+		return nil, err
+	}
+	return result, nil
+}
+
+// exportEvents selects the job's events
+func (e *Exporter) exportEvents(ctx context.Context, job *models.AuditExportJob) *gorm.DB {
+	query := e.db.WithContext(ctx).Model(&models.EventLog{}).
+		Where("tenant_id = ? AND created_at >= ? AND created_at <= ?", job.TenantID, job.StartDate, job.EndDate)
+	if job.EventTypes != nil && *job.EventTypes != "{}" {
+		query = query.Where("event_type = ANY(?::text[])", *job.EventTypes)
+	}
+	return query
+}
+
+// countEvents counts the job's events, for its progress
+func (e *Exporter) countEvents(ctx context.Context, job *models.AuditExportJob) (int64, error) {
+	var total int64
+	if err := e.exportEvents(ctx, job).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return total, nil
+}
+
+// writeEvents streams the job's events to w through a database cursor and
+// returns how many were written
+func (e *Exporter) writeEvents(ctx context.Context, w io.Writer, job *models.AuditExportJob, format ExportFormat, progress *exportProgress) (int, error) {
+	rows, err := e.exportEvents(ctx, job).Order("created_at ASC, id ASC").Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	buffered := bufio.NewWriter(w)
+	encoder := format.NewEncoder(buffered)
+	count := 0
+	for rows.Next() {
+		var event models.EventLog
+		if err := e.db.ScanRows(rows, &event); err != nil {
+			return count, fmt.Errorf("failed to read event: %w", err)
+		}
+		if err := e.logger.DecryptEvent(ctx, &event); err != nil {
+			return count, err
+		}
+		if err := encoder.Write(&event); err != nil {
+			return count, err
+		}
+		count++
+		progress.written.Add(1)
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read events: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return count, err
+	}
+	return count, buffered.Flush()
+}
+
+// encryptFile encrypts a file to a recipient into a new temporary file
+func encryptFile(file *os.File, recipient exportRecipient) (*os.File, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	encrypted, err := os.CreateTemp("", "audit-export-*.enc")
+	if err != nil {
+		return nil, err
+	}
+	w, err := recipient.Encrypt(encrypted)
+	if err == nil {
+		if _, err = io.Copy(w, file); err == nil {
+			err = w.Close()
+		}
+	}
+	if err != nil {
+		encrypted.Close()
+		os.Remove(encrypted.Name())
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// gzipFile compresses a file into a new temporary file
+func gzipFile(file *os.File) (*os.File, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	compressed, err := os.CreateTemp("", "audit-export-*.gz")
+	if err != nil {
+		return nil, err
+	}
+	zw := gzip.NewWriter(compressed)
+	if _, err := io.Copy(zw, file); err != nil {
+		compressed.Close()
+		os.Remove(compressed.Name())
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		compressed.Close()
+		os.Remove(compressed.Name())
+		return nil, err
+	}
+	return compressed, nil
+}
+
+// OpenExport verifies a signed download link and opens the export file
+func (e *Exporter) OpenExport(ctx context.Context, jobID uuid.UUID, expires, signature string) (*models.AuditExportJob, io.ReadCloser, error) {
+	if err := e.urls.Verify(exportDownloadPath(jobID), expires, signature); err != nil {
+		return nil, nil, err
+	}
+
+	var job models.AuditExportJob
+	if err := e.db.WithContext(ctx).Where("id = ? AND status = ?", jobID, "completed").First(&job).Error; err != nil {
+		return nil, nil, fmt.Errorf("export not found")
+	}
+	if job.StorageKey == nil || job.ExpiresAt == nil || job.ExpiresAt.Before(time.Now()) {
+		return nil, nil, fmt.Errorf("link expired")
+	}
+
+	file, err := e.blobs.Open(ctx, *job.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export unavailable: %w", err)
+	}
+	return &job, file, nil
+}
+
+// Worker runs queued export jobs and deletes export files once their
+// download window has passed
+func (e *Exporter) Worker(ctx context.Context) {
+	for i := 0; i < e.concurrency; i++ {
+		go e.runJobs(ctx)
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var jobs []models.AuditExportJob
+			if err := e.db.WithContext(ctx).Where("expires_at < ? AND storage_key IS NOT NULL", time.Now()).
+				Limit(1000).Find(&jobs).Error; err != nil {
+				log.Printf("Error listing expired audit exports: %v", err)
+				continue
+			}
+			for _, job := range jobs {
+				if err := e.blobs.Delete(ctx, *job.StorageKey); err != nil {
+					log.Printf("Error deleting audit export %s: %v", job.ID, err)
+					continue
+				}
+				e.db.WithContext(ctx).Model(&models.AuditExportJob{}).Where("id = ?", job.ID).Update("storage_key", nil)
+			}
+		}
+	}
+}
+
+func exportDownloadPath(id uuid.UUID) string {
+	return "/downloads/audit-exports/" + id.String()
+}
+
+func exportKey(tenantID, jobID uuid.UUID, extension, compression, encryption string) string {
+	key := "audit-exports/" + tenantID.String() + "/" + jobID.String() + "." + extension
+	if compression == "gzip" {
+		key += ".gz"
+	}
+	if encryption != "" {
+		key += "." + exportKeyExtensions[encryption]
+	}
+	return key
+}
+
+// ExportFilename is the download name of a completed export
+func ExportFilename(job *models.AuditExportJob) string {
+	extension := job.Format
+	if format, ok := LookupExportFormat(job.Format); ok {
+		extension = format.Extension
+	}
+	name := "audit-export-" + job.ID.String() + "." + extension
+	if job.Compression != nil && *job.Compression == "gzip" {
+		name += ".gz"
+	}
+	if job.Encryption != nil {
+		name += "." + exportKeyExtensions[*job.Encryption]
+	}
+	return name
+}
+
+func exportContentType(format, compression, encryption string) string {
+	if encryption != "" {
+		return "application/octet-stream"
+	}
+	if compression == "gzip" {
+		return "application/gzip"
+	}
+	if registered, ok := LookupExportFormat(format); ok {
+		return registered.ContentType
+	}
+	return "application/octet-stream"
+}
+
+// validEventType reports whether a value looks like an event type name, such
+// as "consent.issued", so it can be stored in a Postgres array unquoted
+func validEventType(eventType string) bool {
+	if eventType == "" {
+		return false
+	}
+	for _, c := range eventType {
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
 }
 
 func convertMapToJSON(m map[string]interface{}) []byte {
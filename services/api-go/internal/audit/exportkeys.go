@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetExportKey registers the public key the tenant's exports are encrypted
+// to, replacing any previous key. Exports already stored are unaffected.
+func (e *Exporter) SetExportKey(ctx context.Context, tenantID uuid.UUID, keyType, publicKey string) (*models.TenantExportKey, error) {
+	recipient, err := parseExportKey(keyType, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := models.TenantExportKey{
+		TenantID:    tenantID,
+		KeyType:     keyType,
+		PublicKey:   strings.TrimSpace(publicKey),
+		Fingerprint: recipient.Fingerprint(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := e.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"key_type", "public_key", "fingerprint", "updated_at"}),
+	}).Create(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to set export key: %w", err)
+	}
+	if err := e.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to load export key: %w", err)
+	}
+
+	resourceType := "tenant_export_key"
+	e.logger.LogEvent(ctx, LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "audit.export_key.set",
+		ResourceType: &resourceType,
+		Metadata: map[string]interface{}{
+			"key_type":    key.KeyType,
+			"fingerprint": key.Fingerprint,
+		},
+	})
+
+	return &key, nil
+}
+
+// GetExportKey returns the tenant's export key
+func (e *Exporter) GetExportKey(ctx context.Context, tenantID uuid.UUID) (*models.TenantExportKey, error) {
+	var key models.TenantExportKey
+	if err := e.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// DeleteExportKey removes the tenant's export key; later exports are stored
+// unencrypted
+func (e *Exporter) DeleteExportKey(ctx context.Context, tenantID uuid.UUID) error {
+	key, err := e.GetExportKey(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := e.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Delete(&models.TenantExportKey{}).Error; err != nil {
+		return fmt.Errorf("failed to delete export key: %w", err)
+	}
+
+	resourceType := "tenant_export_key"
+	e.logger.LogEvent(ctx, LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "audit.export_key.removed",
+		ResourceType: &resourceType,
+		Metadata: map[string]interface{}{
+			"key_type":    key.KeyType,
+			"fingerprint": key.Fingerprint,
+		},
+	})
+	return nil
+}
+
+// exportRecipientFor returns the recipient the tenant's exports are
+// encrypted to, or nil when the tenant has not registered a key
+func (e *Exporter) exportRecipientFor(ctx context.Context, tenantID uuid.UUID) (*models.TenantExportKey, exportRecipient, error) {
+	key, err := e.GetExportKey(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load export key: %w", err)
+	}
+	recipient, err := parseExportKey(key.KeyType, key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, recipient, nil
+}
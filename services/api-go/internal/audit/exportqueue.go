@@ -0,0 +1,313 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// exportMaxAttempts is how many times a job runs before it is failed
+	exportMaxAttempts = 5
+
+	// exportPollInterval is how often idle runners look for queued jobs
+	exportPollInterval = 5 * time.Second
+
+	// exportHeartbeatInterval is how often a running job renews its lease and
+	// records its progress
+	exportHeartbeatInterval = 10 * time.Second
+
+	// exportLeaseTimeout is how long a running job may go without a
+	// heartbeat before another runner reclaims it
+	exportLeaseTimeout = 2 * time.Minute
+
+	// exportRetryBackoff is the delay before the first retry; it doubles with
+	// each attempt up to exportMaxRetryBackoff
+	exportRetryBackoff    = 30 * time.Second
+	exportMaxRetryBackoff = 30 * time.Minute
+)
+
+// ErrExportFinished is returned when cancelling a job that already finished
+var ErrExportFinished = errors.New("export job already finished")
+
+// exportProgress counts a running job's events
+type exportProgress struct {
+	total   atomic.Int64
+	written atomic.Int64
+}
+
+// percent is the share of events written, held below 100 until the file is stored
+func (p *exportProgress) percent() int {
+	total := p.total.Load()
+	if total == 0 {
+		return 0
+	}
+	percent := int(p.written.Load() * 100 / total)
+	if percent > 99 {
+		percent = 99
+	}
+	return percent
+}
+
+// runJobs claims and runs queued jobs until ctx ends
+func (e *Exporter) runJobs(ctx context.Context) {
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting again
+		for ctx.Err() == nil {
+			job, err := e.claimJob(ctx)
+			if err != nil {
+				log.Printf("Error claiming audit export job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			e.processJob(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.wake:
+		}
+	}
+}
+
+// claimJob leases the next due job: a pending job whose retry delay has
+// passed, or a running job whose runner stopped sending heartbeats. SKIP
+// LOCKED lets runners on every replica claim jobs concurrently without
+// blocking on each other. Returns nil when no job is due.
+func (e *Exporter) claimJob(ctx context.Context) (*models.AuditExportJob, error) {
+	var job models.AuditExportJob
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_after <= ?) OR (status = ? AND heartbeat_at < ?)",
+				"pending", now, "processing", now.Add(-exportLeaseTimeout)).
+			Order("run_after ASC").Take(&job).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"status":       "processing",
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_by":    e.workerID,
+			"heartbeat_at": now,
+		}
+		if job.StartedAt == nil {
+			updates["started_at"] = now
+		}
+		if err := tx.Model(&models.AuditExportJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		job.Status = "processing"
+		job.Attempts++
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// processJob runs a claimed job while renewing its lease. The job stops
+// when its lease is lost, whether to cancellation or to another runner.
+func (e *Exporter) processJob(ctx context.Context, job *models.AuditExportJob) {
+	if job.Attempts > job.MaxAttempts {
+		// Reclaimed after its last attempt's runner died
+		e.retryOrFail(job, fmt.Errorf("export runner stopped responding"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	progress := &exportProgress{}
+	heartbeats := make(chan struct{})
+	go func() {
+		defer close(heartbeats)
+		e.heartbeat(ctx, job, progress, cancel)
+	}()
+
+	result, err := e.buildExport(ctx, job, progress)
+	cancel()
+	<-heartbeats
+
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrInvalidExportKey) {
+			job.Attempts = job.MaxAttempts
+		}
+		e.retryOrFail(job, err)
+		return
+	}
+	e.completeJob(job, result)
+}
+
+// heartbeat renews the job's lease and records its progress until ctx ends.
+// It cancels the job once the lease is lost.
+func (e *Exporter) heartbeat(ctx context.Context, job *models.AuditExportJob, progress *exportProgress, cancel context.CancelFunc) {
+	ticker := time.NewTicker(exportHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed := e.db.WithContext(ctx).Model(&models.AuditExportJob{}).
+				Where("id = ? AND status = ? AND locked_by = ?", job.ID, "processing", e.workerID).
+				Updates(map[string]interface{}{
+					"heartbeat_at":     time.Now(),
+					"progress_percent": progress.percent(),
+				})
+			if renewed.Error != nil {
+				log.Printf("Error renewing audit export %s: %v", job.ID, renewed.Error)
+				continue
+			}
+			if renewed.RowsAffected == 0 {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// completeJob records the stored file and notifies the tenant through the
+// audit.export.completed event
+func (e *Exporter) completeJob(job *models.AuditExportJob, result *exportResult) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":             "completed",
+		"storage_key":        result.key,
+		"file_size_bytes":    result.size,
+		"record_count":       result.count,
+		"file_sha256":        result.sha256,
+		"manifest":           result.manifest,
+		"manifest_signature": result.manifestSignature,
+		"progress_percent":   100,
+		"error_message":      nil,
+		"locked_by":          nil,
+		"completed_at":       now,
+		"expires_at":         now.Add(e.downloadTTL),
+	}
+	if result.compression != "" {
+		updates["compression"] = result.compression
+	}
+	if result.encryption != nil {
+		updates["encryption"] = result.encryption.KeyType
+	}
+	if !e.finishJob(job, updates) {
+		// Cancelled or reclaimed while the file was stored
+		if err := e.blobs.Delete(context.Background(), result.key); err != nil {
+			log.Printf("Error deleting abandoned audit export %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	resourceType := "audit_export"
+	metadata := map[string]interface{}{
+		"format":          job.Format,
+		"record_count":    result.count,
+		"file_size_bytes": result.size,
+		"file_sha256":     result.sha256,
+		"expires_at":      now.Add(e.downloadTTL),
+	}
+	if result.compression != "" {
+		metadata["compression"] = result.compression
+	}
+	if result.encryption != nil {
+		metadata["encryption"] = result.encryption.KeyType
+	}
+	if err := e.logger.LogEvent(context.Background(), LogEventInput{
+		TenantID:     job.TenantID,
+		EventType:    "audit.export.completed",
+		ResourceType: &resourceType,
+		ResourceID:   &job.ID,
+		Metadata:     metadata,
+	}); err != nil {
+		log.Printf("Error logging completion of audit export %s: %v", job.ID, err)
+	}
+}
+
+// retryOrFail requeues a failed attempt with exponential backoff, or fails
+// the job once it has used all its attempts
+func (e *Exporter) retryOrFail(job *models.AuditExportJob, cause error) {
+	updates := map[string]interface{}{
+		"error_message":    cause.Error(),
+		"progress_percent": 0,
+		"locked_by":        nil,
+		"heartbeat_at":     nil,
+	}
+	if job.Attempts < job.MaxAttempts {
+		updates["status"] = "pending"
+		updates["run_after"] = time.Now().Add(exportRetryDelay(job.Attempts))
+	} else {
+		updates["status"] = "failed"
+		updates["completed_at"] = time.Now()
+	}
+	if e.finishJob(job, updates) {
+		log.Printf("Audit export %s attempt %d failed: %v", job.ID, job.Attempts, cause)
+	}
+}
+
+// finishJob ends this runner's lease on a job, reporting false when the
+// lease was already lost
+func (e *Exporter) finishJob(job *models.AuditExportJob, updates map[string]interface{}) bool {
+	result := e.db.Model(&models.AuditExportJob{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, "processing", e.workerID).
+		Updates(updates)
+	if result.Error != nil {
+		log.Printf("Error updating audit export %s: %v", job.ID, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// exportRetryDelay is the backoff after a failed attempt
+func exportRetryDelay(attempts int) time.Duration {
+	delay := exportRetryBackoff
+	for i := 1; i < attempts && delay < exportMaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > exportMaxRetryBackoff {
+		delay = exportMaxRetryBackoff
+	}
+	return delay
+}
+
+// CancelExportJob cancels a pending or running job. A running job stops at
+// its next heartbeat.
+func (e *Exporter) CancelExportJob(ctx context.Context, tenantID uuid.UUID, jobID uuid.UUID) (*models.AuditExportJob, error) {
+	cancelled := e.db.WithContext(ctx).Model(&models.AuditExportJob{}).
+		Where("id = ? AND tenant_id = ? AND status IN ?", jobID, tenantID, []string{"pending", "processing"}).
+		Updates(map[string]interface{}{
+			"status":       "cancelled",
+			"locked_by":    nil,
+			"completed_at": time.Now(),
+		})
+	if cancelled.Error != nil {
+		return nil, fmt.Errorf("failed to cancel export job: %w", cancelled.Error)
+	}
+
+	job, err := e.GetExportJob(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if cancelled.RowsAffected == 0 {
+		return job, ErrExportFinished
+	}
+	return job, nil
+}
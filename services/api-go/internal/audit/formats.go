@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+)
+
+// ExportEncoder writes events in an export format. Events arrive oldest
+// first; Close writes any trailer but leaves the underlying writer open.
+type ExportEncoder interface {
+	Write(event *models.EventLog) error
+	Close() error
+}
+
+// ExportFormat describes an audit export file format
+type ExportFormat struct {
+	ContentType  string
+	Extension    string // File extension, without the leading dot
+	Compressible bool   // Large files are gzipped; false for formats that compress internally
+	NewEncoder   func(w io.Writer) ExportEncoder
+}
+
+var (
+	exportFormatsMu sync.RWMutex
+	exportFormats   = map[string]ExportFormat{
+		"csv":     {ContentType: "text/csv", Extension: "csv", Compressible: true, NewEncoder: newCSVEncoder},
+		"json":    {ContentType: "application/json", Extension: "json", Compressible: true, NewEncoder: newJSONEncoder},
+		"ndjson":  {ContentType: "application/x-ndjson", Extension: "ndjson", Compressible: true, NewEncoder: newNDJSONEncoder},
+		"ocsf":    {ContentType: "application/x-ndjson", Extension: "ocsf.ndjson", Compressible: true, NewEncoder: newOCSFEncoder},
+		"parquet": {ContentType: "application/vnd.apache.parquet", Extension: "parquet", NewEncoder: newParquetEncoder},
+	}
+)
+
+// RegisterExportFormat makes an export format available by name
+func RegisterExportFormat(name string, format ExportFormat) {
+	exportFormatsMu.Lock()
+	defer exportFormatsMu.Unlock()
+	exportFormats[name] = format
+}
+
+// LookupExportFormat returns a registered export format
+func LookupExportFormat(name string) (ExportFormat, bool) {
+	exportFormatsMu.RLock()
+	defer exportFormatsMu.RUnlock()
+	format, ok := exportFormats[name]
+	return format, ok
+}
+
+// ExportFormatNames lists the registered export formats
+func ExportFormatNames() []string {
+	exportFormatsMu.RLock()
+	defer exportFormatsMu.RUnlock()
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// csvEncoder writes one row per event after a header row
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) ExportEncoder {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"ID", "Time", "Event", "Subject", "Resource", "Metadata"})
+	return &csvEncoder{w: writer}
+}
+
+func (c *csvEncoder) Write(l *models.EventLog) error {
+	subj := ""
+	if l.SubjectID != nil {
+		subj = *l.SubjectID
+	}
+	res := ""
+	if l.ResourceID != nil {
+		res = (*l.ResourceID).String()
+	}
+	return c.w.Write([]string{
+		l.ID.String(),
+		l.CreatedAt.Format(time.RFC3339),
+		l.EventType,
+		subj,
+		res,
+		l.Metadata,
+	})
+}
+
+func (c *csvEncoder) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonEncoder writes a JSON array with one event per line
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONEncoder(w io.Writer) ExportEncoder {
+	return &jsonEncoder{w: w}
+}
+
+func (j *jsonEncoder) Write(event *models.EventLog) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if j.count == 0 {
+		separator = "[\n"
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	_, err = j.w.Write(encoded)
+	return err
+}
+
+func (j *jsonEncoder) Close() error {
+	closing := "\n]\n"
+	if j.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// ndjsonEncoder writes one JSON event per line, so consumers can ingest the
+// file as a stream
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) ExportEncoder {
+	return &ndjsonEncoder{encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonEncoder) Write(event *models.EventLog) error {
+	return n.encoder.Encode(event)
+}
+
+func (n *ndjsonEncoder) Close() error {
+	return nil
+}
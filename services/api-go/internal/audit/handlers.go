@@ -1,29 +1,42 @@
 package audit
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
 )
 
 // Handler manages audit-related HTTP endpoints
 type Handler struct {
-	exporter *Exporter
-	// logger *Logger // If we need direct log access
+	exporter     *Exporter
+	logger       *Logger
+	checkpointer *Checkpointer
+	streamer     *Streamer
 }
 
 // NewHandler creates a new audit handler
-func NewHandler(exporter *Exporter) *Handler {
-	return &Handler{exporter: exporter}
+func NewHandler(exporter *Exporter, logger *Logger, checkpointer *Checkpointer, streamer *Streamer) *Handler {
+	return &Handler{exporter: exporter, logger: logger, checkpointer: checkpointer, streamer: streamer}
 }
 
 type CreateExportInput struct {
-	Format    string    `json:"format" binding:"required"` // csv or json
-	StartDate time.Time `json:"start_date" binding:"required"`
-	EndDate   time.Time `json:"end_date" binding:"required"`
+	Format     string    `json:"format" binding:"required"` // A registered format: csv, json, ndjson, parquet or ocsf
+	StartDate  time.Time `json:"start_date" binding:"required"`
+	EndDate    time.Time `json:"end_date" binding:"required"`
+	EventTypes []string  `json:"event_types"` // Only export these event types; all when empty
 }
 
 // CreateExport handles POST /v1/audit/exports
@@ -39,7 +52,14 @@ func (h *Handler) CreateExport(c *gin.Context) {
 
 	tenantID, _ := middleware.GetTenantID(c)
 
-	job, err := h.exporter.CreateExportJob(c.Request.Context(), tenantID, input.Format, input.StartDate, input.EndDate)
+	job, err := h.exporter.CreateExportJob(c.Request.Context(), tenantID, input.Format, input.EventTypes, input.StartDate, input.EndDate)
+	if errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrInvalidEventType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "export_failed",
@@ -76,3 +96,579 @@ func (h *Handler) GetExport(c *gin.Context) {
 
 	c.JSON(http.StatusOK, job)
 }
+
+// CancelExport handles DELETE /v1/audit/exports/:id
+func (h *Handler) CancelExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Job ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	job, err := h.exporter.CancelExportJob(c.Request.Context(), tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Export job not found",
+		})
+		return
+	}
+	if errors.Is(err, ErrExportFinished) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "export_finished",
+			"message": "Export job is already " + job.Status,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "cancel_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+type SetExportKeyInput struct {
+	KeyType   string `json:"key_type" binding:"required"`   // age or openpgp
+	PublicKey string `json:"public_key" binding:"required"` // age1... recipient, or an armored OpenPGP public key
+}
+
+// SetExportKey handles PUT /v1/audit/export-key
+func (h *Handler) SetExportKey(c *gin.Context) {
+	var input SetExportKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	key, err := h.exporter.SetExportKey(c.Request.Context(), tenantID, input.KeyType, input.PublicKey)
+	if errors.Is(err, ErrInvalidExportKey) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_key",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "set_key_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// GetExportKey handles GET /v1/audit/export-key
+func (h *Handler) GetExportKey(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	key, err := h.exporter.GetExportKey(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "No export key registered; exports are stored unencrypted",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// DeleteExportKey handles DELETE /v1/audit/export-key
+func (h *Handler) DeleteExportKey(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	err := h.exporter.DeleteExportKey(c.Request.Context(), tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "No export key registered",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "delete_key_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DownloadExport handles GET /downloads/audit-exports/:id
+// Authorised by the signed link rather than an API key.
+func (h *Handler) DownloadExport(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Job ID must be a valid UUID",
+		})
+		return
+	}
+
+	job, file, err := h.exporter.OpenExport(c.Request.Context(), jobID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "invalid_link",
+			"message": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	size := int64(-1)
+	if job.FileSizeBytes != nil {
+		size = *job.FileSizeBytes
+	}
+	c.DataFromReader(http.StatusOK, size, exportContentType(job.Format, derefString(job.Compression), derefString(job.Encryption)), file, map[string]string{
+		"Content-Disposition": "attachment; filename=\"" + ExportFilename(job) + "\"",
+	})
+}
+
+// ListEvents handles GET /v1/audit/events
+func (h *Handler) ListEvents(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	query := EventQuery{
+		TenantID:     tenantID,
+		Cursor:       c.Query("cursor"),
+		Limit:        limit,
+		IncludeTotal: c.Query("include_total") == "true",
+	}
+
+	if types := c.Query("event_type"); types != "" {
+		query.EventTypes = strings.Split(types, ",")
+	}
+	if subjectID := c.Query("subject_id"); subjectID != "" {
+		query.SubjectID = &subjectID
+	}
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		query.ResourceType = &resourceType
+	}
+	if resourceID := c.Query("resource_id"); resourceID != "" {
+		id, err := uuid.Parse(resourceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": "resource_id must be a valid UUID",
+			})
+			return
+		}
+		query.ResourceID = &id
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query.ActorID = &actorID
+	}
+	var ok bool
+	if query.StartDate, ok = queryTime(c, "start_date"); !ok {
+		return
+	}
+	if query.EndDate, ok = queryTime(c, "end_date"); !ok {
+		return
+	}
+	if filter := c.Query("metadata"); filter != "" {
+		metadata, err := ParseMetadataFilter(filter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+			return
+		}
+		query.Metadata = metadata
+	}
+
+	page, err := h.logger.QueryEvents(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_cursor",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Stream handles GET /v1/audit/stream. Clients get Server-Sent Events, or a
+// WebSocket when they ask to upgrade. Each event carries its chain sequence
+// as its ID; a client resumes after one with Last-Event-ID (or the
+// last_event_id query parameter, for WebSocket clients).
+func (h *Handler) Stream(c *gin.Context) {
+	filter := StreamFilter{}
+	if types := c.Query("event_type"); types != "" {
+		filter.EventTypes = strings.Split(types, ",")
+	}
+	if subjectID := c.Query("subject_id"); subjectID != "" {
+		filter.SubjectID = &subjectID
+	}
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		filter.ResourceType = &resourceType
+	}
+	if resourceID := c.Query("resource_id"); resourceID != "" {
+		id, err := uuid.Parse(resourceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": "resource_id must be a valid UUID",
+			})
+			return
+		}
+		filter.ResourceID = &id
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		filter.ActorID = &actorID
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var resumeAfter *int64
+	if lastEventID != "" {
+		sequence, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || sequence < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": "Last-Event-ID must be an event sequence",
+			})
+			return
+		}
+		resumeAfter = &sequence
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+	tier := "lite"
+	if tenant, ok := middleware.GetTenant(c); ok {
+		tier = tenant.Tier
+	}
+
+	stream, err := h.streamer.Open(c.Request.Context(), tenantID, tier, filter)
+	if err != nil {
+		if errors.Is(err, ErrStreamLimit) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "stream_limit_exceeded",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "stream_unavailable",
+			"message": err.Error(),
+		})
+		return
+	}
+	defer h.streamer.Close(stream)
+
+	// Replay before upgrading, so a resume that cannot be served is refused
+	// with a status instead of an empty stream
+	var backlog []models.EventLog
+	var replayedTo int64
+	if resumeAfter != nil {
+		replayedTo, err = h.streamer.Replay(c.Request.Context(), stream, *resumeAfter, func(event models.EventLog) error {
+			backlog = append(backlog, event)
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, ErrReplayTooLarge) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":   "replay_too_large",
+					"message": err.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "replay_failed",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		h.streamWebSocket(c, stream, backlog, replayedTo)
+		return
+	}
+	h.streamSSE(c, stream, backlog, replayedTo)
+}
+
+// streamMessage is a WebSocket frame of the audit stream
+type streamMessage struct {
+	Type  string           `json:"type"` // event or heartbeat
+	ID    string           `json:"id,omitempty"`
+	Event *models.EventLog `json:"event,omitempty"`
+}
+
+func (h *Handler) streamSSE(c *gin.Context, stream *Stream, backlog []models.EventLog, replayedTo int64) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(event models.EventLog) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\ndata: %s\n\n", *event.Sequence, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	heartbeat := func() error {
+		if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", (5 * time.Second).Milliseconds()); err != nil {
+		return
+	}
+	c.Writer.Flush()
+	h.pump(c.Request.Context(), stream, backlog, replayedTo, send, heartbeat)
+}
+
+func (h *Handler) streamWebSocket(c *gin.Context, stream *Stream, backlog []models.EventLog, replayedTo int64) {
+	server := websocket.Server{
+		// Authenticated by API key rather than cookies, so any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			ctx, cancel := context.WithCancel(c.Request.Context())
+			defer cancel()
+			// The stream is one-way; reading only detects the client closing
+			go func() {
+				io.Copy(io.Discard, conn)
+				cancel()
+			}()
+
+			send := func(event models.EventLog) error {
+				return websocket.JSON.Send(conn, streamMessage{Type: "event", ID: strconv.FormatInt(*event.Sequence, 10), Event: &event})
+			}
+			heartbeat := func() error {
+				return websocket.JSON.Send(conn, streamMessage{Type: "heartbeat"})
+			}
+			h.pump(ctx, stream, backlog, replayedTo, send, heartbeat)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// pump sends the replayed backlog and then live events until the client
+// goes away or the stream falls behind, renewing the stream's slot with
+// each heartbeat
+func (h *Handler) pump(ctx context.Context, stream *Stream, backlog []models.EventLog, replayedTo int64, send func(models.EventLog) error, heartbeat func() error) {
+	for _, event := range backlog {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(StreamHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-stream.Events:
+			if !ok {
+				return
+			}
+			if *event.Sequence <= replayedTo {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := heartbeat(); err != nil {
+				return
+			}
+			if err := h.streamer.Renew(ctx, stream); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// VerifyChain handles GET /v1/audit/verify
+func (h *Handler) VerifyChain(c *gin.Context) {
+	from, ok := queryInt64(c, "from_sequence")
+	if !ok {
+		return
+	}
+	to, ok := queryInt64(c, "to_sequence")
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	result, err := h.logger.VerifyChain(c.Request.Context(), tenantID, VerifyChainInput{FromSequence: from, ToSequence: to})
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_range",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "verify_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListCheckpoints handles GET /v1/audit/checkpoints
+func (h *Handler) ListCheckpoints(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	checkpoints, total, err := h.checkpointer.ListCheckpoints(c.Request.Context(), tenantID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"checkpoints": checkpoints,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetCheckpoint handles GET /v1/audit/checkpoints/:id
+func (h *Handler) GetCheckpoint(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Checkpoint ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	checkpoint, err := h.checkpointer.GetCheckpoint(c.Request.Context(), tenantID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Checkpoint not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoint)
+}
+
+// GetEventProof handles GET /v1/audit/events/:id/proof
+func (h *Handler) GetEventProof(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Event ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	proof, err := h.checkpointer.Proof(c.Request.Context(), tenantID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": "Event not found",
+			})
+		case errors.Is(err, ErrNotCheckpointed):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "not_checkpointed",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "proof_failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+// queryInt64 reads an optional integer query parameter, 0 when absent
+func queryInt64(c *gin.Context, name string) (int64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": name + " must be an integer",
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+// queryTime reads an optional RFC 3339 query parameter, nil when absent
+func queryTime(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": name + " must be an RFC 3339 timestamp",
+		})
+		return nil, false
+	}
+	return &t, true
+}
@@ -2,9 +2,14 @@ package audit
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,12 +18,14 @@ import (
 
 // Logger provides event logging functionality
 type Logger struct {
-	db *gorm.DB
+	db     *gorm.DB
+	crypto *encryption.Service
+	bus    *events.Bus
 }
 
-// NewLogger creates a new audit logger
-func NewLogger(db *gorm.DB) *Logger {
-	return &Logger{db: db}
+// NewLogger creates a new audit logger that publishes recorded events to bus
+func NewLogger(db *gorm.DB, crypto *encryption.Service, bus *events.Bus) *Logger {
+	return &Logger{db: db, crypto: crypto, bus: bus}
 }
 
 // LogEventInput represents data for logging an event
@@ -42,23 +49,60 @@ func (l *Logger) LogEvent(ctx context.Context, input LogEventInput) error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	// Encrypt personal data before it reaches the append-only log
+	encryptedMetadata, err := l.crypto.EncryptJSON(ctx, input.TenantID, string(metadataJSON))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt metadata: %w", err)
+	}
+	encryptedSubject, err := l.crypto.EncryptPtr(ctx, input.TenantID, input.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	encryptedIP, err := l.crypto.EncryptPtr(ctx, input.TenantID, input.IPAddress)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt ip address: %w", err)
+	}
+
+	// Postgres keeps microseconds; the chain hash must see the stored value
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	contentDigest := l.contentDigest(input.TenantID, input.SubjectID, string(metadataJSON), input.IPAddress, input.UserAgent)
+	metadataIndex := toPostgresArray(l.metadataIndex(input.TenantID, metadataTokens(input.Metadata)))
+
 	event := models.EventLog{
-		TenantID:     input.TenantID,
-		EventType:    input.EventType,
-		EventVersion: "v1",
-		ActorID:      input.ActorID,
-		SubjectID:    input.SubjectID,
-		ResourceType: input.ResourceType,
-		ResourceID:   input.ResourceID,
-		Metadata:     string(metadataJSON),
-		IPAddress:    input.IPAddress,
-		UserAgent:    input.UserAgent,
+		ID:            uuid.New(),
+		TenantID:      input.TenantID,
+		EventType:     input.EventType,
+		EventVersion:  "v1",
+		ActorID:       input.ActorID,
+		SubjectID:     encryptedSubject,
+		SubjectIndex:  l.crypto.BlindIndexPtr(input.TenantID, input.SubjectID),
+		MetadataIndex: &metadataIndex,
+		ResourceType:  input.ResourceType,
+		ResourceID:    input.ResourceID,
+		Metadata:      encryptedMetadata,
+		IPAddress:     encryptedIP,
+		UserAgent:     input.UserAgent,
+		CreatedAt:     createdAt,
+		ContentDigest: &contentDigest,
 	}
 
-	if err := l.db.WithContext(ctx).Create(&event).Error; err != nil {
+	if err := l.appendChained(ctx, &event); err != nil {
 		return fmt.Errorf("failed to create event log: %w", err)
 	}
 
+	// Subscribers see the plaintext input, never the sealed row
+	occurredAt := event.CreatedAt
+	l.bus.Publish(ctx, events.Event{
+		ID:           event.ID,
+		TenantID:     input.TenantID,
+		Type:         input.EventType,
+		SubjectID:    input.SubjectID,
+		ResourceType: input.ResourceType,
+		ResourceID:   input.ResourceID,
+		Metadata:     input.Metadata,
+		OccurredAt:   occurredAt,
+	})
+
 	return nil
 }
 
@@ -88,53 +132,38 @@ func (l *Logger) LogEventFromContext(c *gin.Context, input LogEventInput) error
 	return l.LogEvent(c.Request.Context(), input)
 }
 
-// GetEventLog retrieves events with pagination and filtering
-type GetEventLogInput struct {
-	TenantID   uuid.UUID
-	EventTypes []string
-	StartDate  *string
-	EndDate    *string
-	Limit      int
-	Offset     int
-}
-
-// GetEventLog retrieves event logs
-func (l *Logger) GetEventLog(ctx context.Context, input GetEventLogInput) ([]models.EventLog, int64, error) {
-	query := l.db.WithContext(ctx).Where("tenant_id = ?", input.TenantID)
-
-	// Apply filters
-	if len(input.EventTypes) > 0 {
-		query = query.Where("event_type IN ?", input.EventTypes)
-	}
-
-	if input.StartDate != nil {
-		query = query.Where("created_at >= ?", *input.StartDate)
-	}
-
-	if input.EndDate != nil {
-		query = query.Where("created_at <= ?", *input.EndDate)
+// DecryptEvent decrypts the encrypted fields of an event in place
+func (l *Logger) DecryptEvent(ctx context.Context, event *models.EventLog) error {
+	metadata, err := l.crypto.DecryptJSON(ctx, event.TenantID, event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt event %s metadata: %w", event.ID, err)
 	}
-
-	// Get total count
-	var total int64
-	if err := query.Model(&models.EventLog{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count events: %w", err)
+	subject, err := l.crypto.DecryptPtr(ctx, event.TenantID, event.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt event %s subject: %w", event.ID, err)
 	}
-
-	// Apply pagination
-	if input.Limit == 0 {
-		input.Limit = 100
+	ip, err := l.crypto.DecryptPtr(ctx, event.TenantID, event.IPAddress)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt event %s ip address: %w", event.ID, err)
 	}
-	query = query.Limit(input.Limit).Offset(input.Offset)
 
-	// Order by created_at DESC
-	query = query.Order("created_at DESC")
-
-	// Execute query
-	var events []models.EventLog
-	if err := query.Find(&events).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to retrieve events: %w", err)
-	}
+	event.Metadata = metadata
+	event.SubjectID = subject
+	event.IPAddress = ip
+	return nil
+}
 
-	return events, total, nil
+// PersonalDataDigest hashes the personal data fields of a decrypted event.
+// Subject erasure keeps this digest in a tombstone so the redacted event can
+// still be matched against its original content.
+func PersonalDataDigest(event models.EventLog) string {
+	content, _ := json.Marshal(struct {
+		SubjectID *string `json:"subject_id"`
+		Metadata  string  `json:"metadata"`
+		IPAddress *string `json:"ip_address"`
+		UserAgent *string `json:"user_agent"`
+	}{event.SubjectID, event.Metadata, event.IPAddress, event.UserAgent})
+
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
 }
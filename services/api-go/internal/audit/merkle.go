@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Merkle trees follow RFC 6962: leaves and interior nodes are hashed with
+// distinct prefixes, and a tree of n leaves splits at the largest power of
+// two below n. Any RFC 6962 / RFC 9162 client can verify the proofs.
+
+// MerkleLeafHash hashes an event's chain hash as a tree leaf
+func MerkleLeafHash(eventHash []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(eventHash)
+	return h.Sum(nil)
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// MerkleRoot computes the root of a tree over leaf hashes
+func MerkleRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return merkleNodeHash(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// MerkleAuditPath returns the sibling hashes from a leaf up to the root
+func MerkleAuditPath(index int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if index < k {
+		return append(MerkleAuditPath(index, leaves[:k]), MerkleRoot(leaves[k:]))
+	}
+	return append(MerkleAuditPath(index-k, leaves[k:]), MerkleRoot(leaves[:k]))
+}
+
+// splitPoint is the largest power of two smaller than n
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func hexHashes(hashes [][]byte) []string {
+	out := make([]string, len(hashes))
+	for i, hash := range hashes {
+		out[i] = hex.EncodeToString(hash)
+	}
+	return out
+}
@@ -0,0 +1,228 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+)
+
+// OCSF API Activity class (category Application Activity), which fits every
+// audit event: each records an operation through the API
+const (
+	ocsfVersion       = "1.1.0"
+	ocsfCategoryUID   = 6
+	ocsfClassUID      = 6003
+	ocsfSeverityInfo  = 1
+	ocsfStatusSuccess = 1
+	ocsfStatusFailure = 2
+)
+
+// OCSF API Activity activities
+const (
+	ocsfActivityCreate = 1
+	ocsfActivityRead   = 2
+	ocsfActivityUpdate = 3
+	ocsfActivityDelete = 4
+	ocsfActivityOther  = 99
+)
+
+var ocsfActivityNames = map[int]string{
+	ocsfActivityCreate: "Create",
+	ocsfActivityRead:   "Read",
+	ocsfActivityUpdate: "Update",
+	ocsfActivityDelete: "Delete",
+	ocsfActivityOther:  "Other",
+}
+
+// ocsfActivities maps the verb ending an event type to an activity. Deletes
+// are checked first so "federation_unlinked" is not taken for "linked".
+var ocsfActivities = []struct {
+	suffix   string
+	activity int
+}{
+	{"revoked", ocsfActivityDelete},
+	{"erased", ocsfActivityDelete},
+	{"unlinked", ocsfActivityDelete},
+	{"read", ocsfActivityRead},
+	{"issued", ocsfActivityCreate},
+	{"initiated", ocsfActivityCreate},
+	{"created", ocsfActivityCreate},
+	{"recorded", ocsfActivityCreate},
+	{"filed", ocsfActivityCreate},
+	{"requested", ocsfActivityCreate},
+	{"awarded", ocsfActivityCreate},
+	{"linked", ocsfActivityCreate},
+	{"accepted", ocsfActivityUpdate},
+	{"declined", ocsfActivityUpdate},
+	{"completed", ocsfActivityUpdate},
+	{"verified", ocsfActivityUpdate},
+	{"failed", ocsfActivityUpdate},
+	{"pending", ocsfActivityUpdate},
+	{"expired", ocsfActivityUpdate},
+	{"changed", ocsfActivityUpdate},
+	{"crossed", ocsfActivityUpdate},
+	{"activated", ocsfActivityUpdate},
+	{"overturned", ocsfActivityUpdate},
+	{"dismissed", ocsfActivityUpdate},
+	{"set", ocsfActivityUpdate},
+}
+
+type ocsfEvent struct {
+	ActivityID   int              `json:"activity_id"`
+	ActivityName string           `json:"activity_name"`
+	CategoryUID  int              `json:"category_uid"`
+	CategoryName string           `json:"category_name"`
+	ClassUID     int              `json:"class_uid"`
+	ClassName    string           `json:"class_name"`
+	TypeUID      int              `json:"type_uid"`
+	TypeName     string           `json:"type_name"`
+	SeverityID   int              `json:"severity_id"`
+	Severity     string           `json:"severity"`
+	StatusID     int              `json:"status_id"`
+	Status       string           `json:"status"`
+	Time         int64            `json:"time"` // Milliseconds since the epoch
+	Metadata     ocsfMetadata     `json:"metadata"`
+	API          ocsfAPI          `json:"api"`
+	Actor        ocsfActor        `json:"actor"`
+	SrcEndpoint  ocsfEndpoint     `json:"src_endpoint"`
+	HTTPRequest  *ocsfHTTPRequest `json:"http_request,omitempty"`
+	Resources    []ocsfResource   `json:"resources,omitempty"`
+	Unmapped     ocsfUnmapped     `json:"unmapped"`
+}
+
+type ocsfMetadata struct {
+	UID       string      `json:"uid"`
+	Version   string      `json:"version"`
+	Product   ocsfProduct `json:"product"`
+	LogName   string      `json:"log_name"`
+	EventCode string      `json:"event_code"`
+	TenantUID string      `json:"tenant_uid"`
+	Sequence  *int64      `json:"sequence,omitempty"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+type ocsfAPI struct {
+	Operation string `json:"operation"`
+	Version   string `json:"version"`
+}
+
+type ocsfActor struct {
+	User *ocsfUser `json:"user,omitempty"`
+}
+
+type ocsfUser struct {
+	UID string `json:"uid"`
+}
+
+type ocsfEndpoint struct {
+	IP *string `json:"ip,omitempty"`
+}
+
+type ocsfHTTPRequest struct {
+	UserAgent string `json:"user_agent"`
+}
+
+type ocsfResource struct {
+	UID  *string `json:"uid,omitempty"`
+	Type *string `json:"type,omitempty"`
+}
+
+// ocsfUnmapped keeps the event fields OCSF has no attribute for
+type ocsfUnmapped struct {
+	SubjectID *string         `json:"subject_id,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	PrevHash  *string         `json:"prev_hash,omitempty"`
+	Hash      *string         `json:"hash,omitempty"`
+}
+
+// ocsfEncoder writes one OCSF API Activity event per line
+type ocsfEncoder struct {
+	encoder *json.Encoder
+}
+
+func newOCSFEncoder(w io.Writer) ExportEncoder {
+	return &ocsfEncoder{encoder: json.NewEncoder(w)}
+}
+
+func (o *ocsfEncoder) Write(event *models.EventLog) error {
+	return o.encoder.Encode(toOCSF(event))
+}
+
+func (o *ocsfEncoder) Close() error {
+	return nil
+}
+
+// toOCSF maps an audit event to an OCSF API Activity event
+func toOCSF(event *models.EventLog) ocsfEvent {
+	activity := ocsfActivity(event.EventType)
+	status, statusName := ocsfStatusSuccess, "Success"
+	if strings.HasSuffix(event.EventType, "failed") {
+		status, statusName = ocsfStatusFailure, "Failure"
+	}
+
+	mapped := ocsfEvent{
+		ActivityID:   activity,
+		ActivityName: ocsfActivityNames[activity],
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: "Application Activity",
+		ClassUID:     ocsfClassUID,
+		ClassName:    "API Activity",
+		TypeUID:      ocsfClassUID*100 + activity,
+		TypeName:     "API Activity: " + ocsfActivityNames[activity],
+		SeverityID:   ocsfSeverityInfo,
+		Severity:     "Informational",
+		StatusID:     status,
+		Status:       statusName,
+		Time:         event.CreatedAt.UnixMilli(),
+		Metadata: ocsfMetadata{
+			UID:       event.ID.String(),
+			Version:   ocsfVersion,
+			Product:   ocsfProduct{Name: "Mighty Eagle Trust Layer", VendorName: "Mighty Eagle"},
+			LogName:   "event_log",
+			EventCode: event.EventType,
+			TenantUID: event.TenantID.String(),
+			Sequence:  event.Sequence,
+		},
+		API:         ocsfAPI{Operation: event.EventType, Version: event.EventVersion},
+		SrcEndpoint: ocsfEndpoint{IP: event.IPAddress},
+		Unmapped: ocsfUnmapped{
+			SubjectID: event.SubjectID,
+			PrevHash:  event.PrevHash,
+			Hash:      event.Hash,
+		},
+	}
+	if event.ActorID != nil {
+		mapped.Actor.User = &ocsfUser{UID: *event.ActorID}
+	}
+	if event.UserAgent != nil {
+		mapped.HTTPRequest = &ocsfHTTPRequest{UserAgent: *event.UserAgent}
+	}
+	if event.ResourceType != nil || event.ResourceID != nil {
+		resource := ocsfResource{Type: event.ResourceType}
+		if event.ResourceID != nil {
+			uid := event.ResourceID.String()
+			resource.UID = &uid
+		}
+		mapped.Resources = []ocsfResource{resource}
+	}
+	if json.Valid([]byte(event.Metadata)) {
+		mapped.Unmapped.Metadata = json.RawMessage(event.Metadata)
+	}
+	return mapped
+}
+
+// ocsfActivity classifies an event type by the verb that ends it
+func ocsfActivity(eventType string) int {
+	for _, candidate := range ocsfActivities {
+		if strings.HasSuffix(eventType, candidate.suffix) {
+			return candidate.activity
+		}
+	}
+	return ocsfActivityOther
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// testParquetEvent builds an event whose optional fields are null on every
+// other row
+func testParquetEvent(tenantID uuid.UUID, i int) *models.EventLog {
+	event := &models.EventLog{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		EventType:    "persona.verified",
+		EventVersion: "v1",
+		Metadata:     fmt.Sprintf(`{"row":%d}`, i),
+		CreatedAt:    time.Unix(1700000000, 0).Add(time.Duration(i) * time.Second).UTC(),
+	}
+	if i%2 == 0 {
+		actor := fmt.Sprintf("actor-%d", i)
+		resourceID := uuid.New()
+		sequence := int64(i + 1)
+		event.ActorID = &actor
+		event.ResourceID = &resourceID
+		event.Sequence = &sequence
+	}
+	return event
+}
+
+// TestParquetEncoderRoundTrip reads an export back: its schema, its row
+// groups and every row
+func TestParquetEncoderRoundTrip(t *testing.T) {
+	tenantID := uuid.New()
+	rows := parquetRowGroupSize + 25 // A full row group and a partial one
+
+	var file bytes.Buffer
+	encoder := newParquetEncoder(&file)
+	var written []parquetRow
+	for i := 0; i < rows; i++ {
+		event := testParquetEvent(tenantID, i)
+		written = append(written, newParquetRow(event))
+		if err := encoder.Write(event); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(file.Bytes()), int64(file.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if f.NumRows() != int64(rows) {
+		t.Fatalf("file has %d rows, want %d", f.NumRows(), rows)
+	}
+	if len(f.RowGroups()) != 2 {
+		t.Fatalf("file has %d row groups, want 2", len(f.RowGroups()))
+	}
+	for _, group := range f.Metadata().RowGroups {
+		for _, chunk := range group.Columns {
+			if chunk.MetaData.Codec != format.Gzip {
+				t.Fatalf("column %v uses codec %v, want gzip", chunk.MetaData.PathInSchema, chunk.MetaData.Codec)
+			}
+		}
+	}
+
+	fields := f.Schema().Fields()
+	wantOptional := map[string]bool{"actor_id": true, "subject_id": true, "resource_type": true, "resource_id": true,
+		"ip_address": true, "user_agent": true, "sequence": true, "hash": true}
+	if len(fields) != 14 {
+		t.Fatalf("schema has %d columns, want 14", len(fields))
+	}
+	for _, field := range fields {
+		if field.Optional() != wantOptional[field.Name()] {
+			t.Errorf("column %s: optional %v", field.Name(), field.Optional())
+		}
+	}
+
+	reader := parquet.NewGenericReader[parquetRow](f)
+	defer reader.Close()
+	read := make([]parquetRow, rows)
+	if n, err := reader.Read(read); n != rows || (err != nil && err != io.EOF) {
+		t.Fatalf("read %d rows (%v), want %d", n, err, rows)
+	}
+	for i := range written {
+		if !reflect.DeepEqual(read[i], written[i]) {
+			t.Fatalf("row %d: got %+v, want %+v", i, read[i], written[i])
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultEventPageSize is the page size when none is requested
+	DefaultEventPageSize = 100
+
+	// MaxEventPageSize caps the events returned per page
+	MaxEventPageSize = 1000
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor this API did not issue
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidMetadataFilter is returned for a metadata filter that is not a JSON object
+	ErrInvalidMetadataFilter = errors.New("metadata filter must be a JSON object")
+)
+
+// EventQuery filters a tenant's audit events. Events are returned newest
+// first and paged by keyset on (created_at, id).
+type EventQuery struct {
+	TenantID     uuid.UUID
+	EventTypes   []string
+	SubjectID    *string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	ActorID      *string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Metadata     map[string]interface{} // Matches events whose metadata contains it, as jsonb @> would
+	Cursor       string
+	Limit        int
+	IncludeTotal bool // Adds the planner's row estimate for the filters
+}
+
+// EventPage is one page of audit events
+type EventPage struct {
+	Events        []models.EventLog `json:"events"`
+	NextCursor    *string           `json:"next_cursor"`
+	HasMore       bool              `json:"has_more"`
+	TotalEstimate *int64            `json:"total_estimate,omitempty"`
+}
+
+// eventCursor is the position after the last event of a page
+type eventCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// QueryEvents returns a page of the tenant's audit events
+func (l *Logger) QueryEvents(ctx context.Context, input EventQuery) (*EventPage, error) {
+	if input.Limit <= 0 {
+		input.Limit = DefaultEventPageSize
+	}
+	if input.Limit > MaxEventPageSize {
+		input.Limit = MaxEventPageSize
+	}
+
+	filtered := func() *gorm.DB {
+		query := l.db.WithContext(ctx).Model(&models.EventLog{}).Where("tenant_id = ?", input.TenantID)
+		if len(input.EventTypes) > 0 {
+			query = query.Where("event_type IN ?", input.EventTypes)
+		}
+		if input.SubjectID != nil {
+			query = query.Where("subject_index = ?", l.crypto.BlindIndex(input.TenantID, *input.SubjectID))
+		}
+		if input.ResourceType != nil {
+			query = query.Where("resource_type = ?", *input.ResourceType)
+		}
+		if input.ResourceID != nil {
+			query = query.Where("resource_id = ?", *input.ResourceID)
+		}
+		if input.ActorID != nil {
+			query = query.Where("actor_id = ?", *input.ActorID)
+		}
+		if input.StartDate != nil {
+			query = query.Where("created_at >= ?", *input.StartDate)
+		}
+		if input.EndDate != nil {
+			query = query.Where("created_at <= ?", *input.EndDate)
+		}
+		if tokens := metadataTokens(input.Metadata); len(tokens) > 0 {
+			query = query.Where("metadata_index @> ?::text[]", toPostgresArray(l.metadataIndex(input.TenantID, tokens)))
+		}
+		return query
+	}
+
+	query := filtered()
+	if input.Cursor != "" {
+		cursor, err := decodeEventCursor(input.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	var events []models.EventLog
+	if err := query.Order("created_at DESC, id DESC").Limit(input.Limit + 1).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve events: %w", err)
+	}
+
+	page := &EventPage{Events: events}
+	if len(events) > input.Limit {
+		page.Events = events[:input.Limit]
+		page.HasMore = true
+		last := page.Events[len(page.Events)-1]
+		cursor := encodeEventCursor(eventCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		page.NextCursor = &cursor
+	}
+
+	for i := range page.Events {
+		if err := l.DecryptEvent(ctx, &page.Events[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.IncludeTotal {
+		estimate, err := l.estimateRows(ctx, filtered().Select("id"))
+		if err != nil {
+			return nil, err
+		}
+		page.TotalEstimate = &estimate
+	}
+
+	return page, nil
+}
+
+// estimateRows returns the planner's row estimate for a query, which costs
+// the same on any tenant size where COUNT(*) scans every match
+func (l *Logger) estimateRows(ctx context.Context, query *gorm.DB) (int64, error) {
+	var plan string
+	if err := l.db.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) ?", query).Row().Scan(&plan); err != nil {
+		return 0, fmt.Errorf("failed to estimate events: %w", err)
+	}
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil || len(explained) == 0 {
+		return 0, fmt.Errorf("failed to parse query plan: %v", err)
+	}
+	return int64(explained[0].Plan.Rows), nil
+}
+
+// metadataIndex blind-indexes metadata tokens, so the stored index reveals
+// nothing about the values without the index key
+func (l *Logger) metadataIndex(tenantID uuid.UUID, tokens []string) []string {
+	index := make([]string, len(tokens))
+	for i, token := range tokens {
+		index[i] = l.crypto.BlindIndex(tenantID, token)
+	}
+	return index
+}
+
+// metadataTokens flattens a metadata document into sorted, distinct
+// (path, value) tokens. Array elements share their array's path, so a document
+// containing another yields a superset of its tokens, mirroring jsonb @> for
+// objects and arrays of scalars.
+func metadataTokens(metadata map[string]interface{}) []string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	// Round-trip through JSON so Go and decoded values encode alike
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+	var document interface{}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var walk func(path []string, value interface{})
+	walk = func(path []string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				walk(append(path[:len(path):len(path)], key), child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(path, child)
+			}
+		default:
+			token, _ := json.Marshal([]interface{}{path, v})
+			seen[string(token)] = true
+		}
+	}
+	walk([]string{}, document)
+
+	tokens := make([]string, 0, len(seen))
+	for token := range seen {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// ParseMetadataFilter parses a metadata containment filter
+func ParseMetadataFilter(filter string) (map[string]interface{}, error) {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(filter), &metadata); err != nil || metadata == nil {
+		return nil, ErrInvalidMetadataFilter
+	}
+	return metadata, nil
+}
+
+func encodeEventCursor(cursor eventCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeEventCursor(value string) (*eventCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor eventCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// toPostgresArray formats blind indexes as a Postgres array literal; hex
+// digests need no escaping
+func toPostgresArray(values []string) string {
+	return "{" + strings.Join(values, ",") + "}"
+}
@@ -0,0 +1,391 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// streamChannelPrefix prefixes the per-tenant Redis channel of new events
+	streamChannelPrefix = "audit:stream:events:"
+
+	// streamConnectionsPrefix prefixes the per-tenant sorted set of open streams
+	streamConnectionsPrefix = "audit:stream:connections:"
+
+	// StreamHeartbeat is how often open streams send a keepalive and renew their slot
+	StreamHeartbeat = 15 * time.Second
+
+	// streamSlotTTL expires the slot of a stream whose replica died
+	streamSlotTTL = 4 * StreamHeartbeat
+
+	// streamBufferSize is the events a slow stream may fall behind before it is closed
+	streamBufferSize = 256
+
+	// MaxStreamReplay caps the events replayed when resuming a stream
+	MaxStreamReplay = 10000
+)
+
+// StreamConnectionLimits caps the open streams per tenant, by tier
+var StreamConnectionLimits = map[string]int{
+	"lite":       2,
+	"pro":        10,
+	"enterprise": 50,
+}
+
+var (
+	// ErrStreamLimit is returned when the tenant has no stream slots left
+	ErrStreamLimit = errors.New("stream connection limit reached")
+
+	// ErrReplayTooLarge is returned when a resume would replay more than MaxStreamReplay events
+	ErrReplayTooLarge = errors.New("too many events to replay")
+)
+
+// acquireSlot atomically drops expired slots and claims one if the tenant is
+// under its limit. Scores are the slot's last renewal in milliseconds.
+var acquireSlot = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - ttl)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ttl)
+return 1
+`)
+
+// Streamer fans new audit events out to live streams. Each replica
+// publishes a notice of the events it records to Redis, and on each notice
+// every replica loads the tenant's events after the last one it delivered
+// and hands them to its own streams, so a stream sees events from all
+// replicas. Notices from different replicas arrive in any order, but
+// sequences are assigned under the chain head lock and so commit in order:
+// loading by sequence delivers events in chain order, without gaps, and a
+// lost notice is made up by the next. Only notices cross Redis; events are
+// loaded and decrypted locally.
+type Streamer struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	logger      *Logger
+
+	mu        sync.Mutex
+	streams   map[uuid.UUID]map[*Stream]struct{}
+	delivered map[uuid.UUID]int64 // Sequence delivered to each tenant's streams
+}
+
+// NewStreamer creates a new audit event streamer
+func NewStreamer(db *gorm.DB, redisClient *redis.Client, logger *Logger) *Streamer {
+	return &Streamer{
+		db:          db,
+		redisClient: redisClient,
+		logger:      logger,
+		streams:     make(map[uuid.UUID]map[*Stream]struct{}),
+		delivered:   make(map[uuid.UUID]int64),
+	}
+}
+
+// StreamFilter selects the events a stream receives
+type StreamFilter struct {
+	EventTypes   []string // Patterns as for webhooks: "persona.verified", "consent.*"
+	SubjectID    *string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	ActorID      *string
+}
+
+// Stream is one client's live view of its tenant's events. Events is closed
+// when the client falls too far behind; it should reconnect and resume.
+type Stream struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	Events   chan models.EventLog
+
+	filter     StreamFilter
+	replayedTo int64 // Live events up to this sequence are sent by Replay
+}
+
+type streamNotice struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	EventID  uuid.UUID `json:"event_id"`
+}
+
+// HandleEvent publishes a recorded event to every replica's streams
+func (s *Streamer) HandleEvent(ctx context.Context, event events.Event) {
+	notice, _ := json.Marshal(streamNotice{TenantID: event.TenantID, EventID: event.ID})
+	if err := s.redisClient.Publish(ctx, streamChannelPrefix+event.TenantID.String(), notice).Err(); err != nil {
+		log.Printf("Failed to publish audit event %s to streams: %v", event.ID, err)
+	}
+}
+
+// Worker delivers events published by any replica to this replica's streams
+func (s *Streamer) Worker(ctx context.Context) {
+	pubsub := s.redisClient.PSubscribe(ctx, streamChannelPrefix+"*")
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var notice streamNotice
+			if err := json.Unmarshal([]byte(message.Payload), &notice); err != nil {
+				log.Printf("Malformed audit stream notice: %v", err)
+				continue
+			}
+			s.dispatch(ctx, notice)
+		}
+	}
+}
+
+// dispatch loads the tenant's events after the last one delivered, in
+// sequence order, and hands each to the tenant's matching streams
+func (s *Streamer) dispatch(ctx context.Context, notice streamNotice) {
+	for {
+		s.mu.Lock()
+		after, listening := s.delivered[notice.TenantID]
+		s.mu.Unlock()
+		if !listening {
+			return
+		}
+
+		var batch []models.EventLog
+		if err := s.db.WithContext(ctx).Where("tenant_id = ? AND sequence > ?", notice.TenantID, after).
+			Order("sequence ASC").Limit(verifyBatchSize).Find(&batch).Error; err != nil {
+			log.Printf("Failed to load streamed audit events after %d: %v", after, err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+		for i := range batch {
+			event := &batch[i]
+			if err := s.logger.DecryptEvent(ctx, event); err != nil {
+				// Skipping would leave a gap; the next notice retries from here
+				log.Printf("Failed to decrypt streamed audit event %s: %v", event.ID, err)
+				return
+			}
+			s.deliver(notice.TenantID, event)
+		}
+	}
+}
+
+// deliver hands an event to the tenant's matching streams and records it as
+// delivered
+func (s *Streamer) deliver(tenantID uuid.UUID, event *models.EventLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if after, ok := s.delivered[tenantID]; !ok || *event.Sequence <= after {
+		// Every stream closed meanwhile, or a reopened one starts later
+		return
+	}
+	s.delivered[tenantID] = *event.Sequence
+
+	for stream := range s.streams[tenantID] {
+		if *event.Sequence <= stream.replayedTo || !stream.filter.matches(event) {
+			continue
+		}
+		select {
+		case stream.Events <- *event:
+		default:
+			// Too far behind; the client resumes from its last event
+			s.remove(stream)
+			close(stream.Events)
+		}
+	}
+}
+
+// Open claims one of the tenant's stream slots and starts receiving its new
+// events. The caller must Close the stream.
+func (s *Streamer) Open(ctx context.Context, tenantID uuid.UUID, tier string, filter StreamFilter) (*Stream, error) {
+	limit, ok := StreamConnectionLimits[tier]
+	if !ok {
+		limit = StreamConnectionLimits["lite"]
+	}
+
+	stream := &Stream{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		Events:   make(chan models.EventLog, streamBufferSize),
+		filter:   filter,
+	}
+	acquired, err := acquireSlot.Run(ctx, s.redisClient, []string{streamConnectionsPrefix + tenantID.String()},
+		time.Now().UnixMilli(), streamSlotTTL.Milliseconds(), limit, stream.ID.String()).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim stream slot: %w", err)
+	}
+	if acquired == 0 {
+		return nil, fmt.Errorf("%w: %d open streams allowed", ErrStreamLimit, limit)
+	}
+
+	// Live delivery starts after the chain head, unless the tenant already
+	// has streams here and so a position
+	head, err := s.headSequence(ctx, tenantID)
+	if err != nil {
+		s.Close(stream)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.streams[tenantID] == nil {
+		s.streams[tenantID] = make(map[*Stream]struct{})
+	}
+	s.streams[tenantID][stream] = struct{}{}
+	if _, ok := s.delivered[tenantID]; !ok {
+		s.delivered[tenantID] = head
+	}
+	s.mu.Unlock()
+	return stream, nil
+}
+
+// headSequence returns the sequence of the tenant's chain head, or 0 before
+// its first event
+func (s *Streamer) headSequence(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var head models.EventLogChainHead
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&head).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to load chain head: %w", err)
+	}
+	return head.Sequence, nil
+}
+
+// Renew keeps the stream's slot from expiring; call it every StreamHeartbeat
+func (s *Streamer) Renew(ctx context.Context, stream *Stream) error {
+	key := streamConnectionsPrefix + stream.TenantID.String()
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAddXX(ctx, key, redis.Z{Score: float64(time.Now().UnixMilli()), Member: stream.ID.String()})
+		pipe.PExpire(ctx, key, streamSlotTTL)
+		return nil
+	})
+	return err
+}
+
+// Close stops delivery to the stream and releases its slot
+func (s *Streamer) Close(stream *Stream) {
+	s.mu.Lock()
+	s.remove(stream)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.redisClient.ZRem(ctx, streamConnectionsPrefix+stream.TenantID.String(), stream.ID.String()).Err(); err != nil {
+		log.Printf("Failed to release stream slot %s: %v", stream.ID, err)
+	}
+}
+
+// remove unregisters a stream; s.mu must be held
+func (s *Streamer) remove(stream *Stream) {
+	tenantStreams := s.streams[stream.TenantID]
+	delete(tenantStreams, stream)
+	if len(tenantStreams) == 0 {
+		delete(s.streams, stream.TenantID)
+		delete(s.delivered, stream.TenantID)
+	}
+}
+
+// Replay sends the stream's matching events after a sequence, oldest first,
+// up to the chain head, and returns the head's sequence. Sequences are
+// assigned under the chain head lock, so every event up to the head is
+// already committed, and live events at or below it are duplicates the
+// caller must skip.
+func (s *Streamer) Replay(ctx context.Context, stream *Stream, after int64, send func(models.EventLog) error) (int64, error) {
+	head, err := s.headSequence(ctx, stream.TenantID)
+	if err != nil || head == 0 {
+		return 0, err
+	}
+	if head-after > MaxStreamReplay {
+		return 0, fmt.Errorf("%w: %d events since %d; page through /v1/audit/events instead", ErrReplayTooLarge, head-after, after)
+	}
+
+	// Stop buffering live events the replay covers
+	s.mu.Lock()
+	stream.replayedTo = head
+	s.mu.Unlock()
+
+	for {
+		query := s.db.WithContext(ctx).Where("tenant_id = ? AND sequence > ? AND sequence <= ?", stream.TenantID, after, head)
+		if stream.filter.SubjectID != nil {
+			query = query.Where("subject_index = ?", s.logger.crypto.BlindIndex(stream.TenantID, *stream.filter.SubjectID))
+		}
+		if stream.filter.ResourceType != nil {
+			query = query.Where("resource_type = ?", *stream.filter.ResourceType)
+		}
+		if stream.filter.ResourceID != nil {
+			query = query.Where("resource_id = ?", *stream.filter.ResourceID)
+		}
+		if stream.filter.ActorID != nil {
+			query = query.Where("actor_id = ?", *stream.filter.ActorID)
+		}
+
+		var batch []models.EventLog
+		if err := query.Order("sequence ASC").Limit(verifyBatchSize).Find(&batch).Error; err != nil {
+			return 0, fmt.Errorf("failed to load events to replay: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			event := &batch[i]
+			after = *event.Sequence
+			if !stream.filter.matchesType(event.EventType) {
+				continue
+			}
+			if err := s.logger.DecryptEvent(ctx, event); err != nil {
+				return 0, err
+			}
+			if err := send(*event); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return head, nil
+}
+
+// matches reports whether a decrypted event passes the filter
+func (f StreamFilter) matches(event *models.EventLog) bool {
+	if !f.matchesType(event.EventType) {
+		return false
+	}
+	if f.SubjectID != nil && (event.SubjectID == nil || *event.SubjectID != *f.SubjectID) {
+		return false
+	}
+	if f.ResourceType != nil && (event.ResourceType == nil || *event.ResourceType != *f.ResourceType) {
+		return false
+	}
+	if f.ResourceID != nil && (event.ResourceID == nil || *event.ResourceID != *f.ResourceID) {
+		return false
+	}
+	if f.ActorID != nil && (event.ActorID == nil || *event.ActorID != *f.ActorID) {
+		return false
+	}
+	return true
+}
+
+func (f StreamFilter) matchesType(eventType string) bool {
+	if len(f.EventTypes) == 0 {
+		return true
+	}
+	for _, pattern := range f.EventTypes {
+		if events.Match(strings.TrimSpace(pattern), eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package consent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ScopeReputationFederation lets the tenants named in a token's parties read
+// the subject's reputation at the issuing tenant
+const ScopeReputationFederation = "reputation.federation"
+
+// TenantPartyPrefix marks a party that names a tenant rather than a subject
+const TenantPartyPrefix = "tenant:"
+
+// ErrInvalidParties is returned when a token's parties do not fit its scope
+var ErrInvalidParties = errors.New("invalid parties")
+
+// FederationParties splits the parties of a federation consent into the
+// consenting subject and the tenants allowed to read their reputation
+func FederationParties(issuer uuid.UUID, parties []string) (string, []uuid.UUID, error) {
+	var subjectID string
+	var tenants []uuid.UUID
+	for _, party := range parties {
+		if !strings.HasPrefix(party, TenantPartyPrefix) {
+			if subjectID != "" {
+				return "", nil, fmt.Errorf("%w: federation consent names exactly one subject", ErrInvalidParties)
+			}
+			subjectID = party
+			continue
+		}
+		tenantID, err := uuid.Parse(strings.TrimPrefix(party, TenantPartyPrefix))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: '%s' is not a tenant ID", ErrInvalidParties, party)
+		}
+		if tenantID == issuer {
+			return "", nil, fmt.Errorf("%w: a tenant cannot federate with itself", ErrInvalidParties)
+		}
+		tenants = append(tenants, tenantID)
+	}
+	if subjectID == "" || len(tenants) == 0 {
+		return "", nil, fmt.Errorf("%w: federation consent names a subject and at least one %s<id> party", ErrInvalidParties, TenantPartyPrefix)
+	}
+	return subjectID, tenants, nil
+}
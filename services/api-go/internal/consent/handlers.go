@@ -1,6 +1,7 @@
 package consent
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
@@ -43,6 +44,13 @@ func (h *Handler) CreateToken(c *gin.Context) {
 
 	token, err := h.service.CreateToken(c.Request.Context(), tenantID, tenantSecret, input)
 	if err != nil {
+		if errors.Is(err, ErrInvalidParties) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_parties",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "create_token_failed",
 			"message": err.Error(),
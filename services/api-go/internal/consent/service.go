@@ -33,6 +33,12 @@ type CreateTokenInput struct {
 
 // CreateToken issues a new consent token
 func (s *Service) CreateToken(ctx context.Context, tenantID uuid.UUID, tenantSecret string, input CreateTokenInput) (*models.ConsentToken, error) {
+	if input.Scope == ScopeReputationFederation {
+		if _, _, err := FederationParties(tenantID, input.Parties); err != nil {
+			return nil, err
+		}
+	}
+
 	// 1. Generate unique hash to prevent duplicates if business rule requires unique active consent per scope
 	tokenHash := GenerateTokenHash(tenantID, input.Parties, input.Scope)
 	
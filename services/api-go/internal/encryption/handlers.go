@@ -0,0 +1,61 @@
+package encryption
+
+import (
+	"net/http"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler manages encryption-related HTTP endpoints
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new encryption handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateKeyRotation handles POST /v1/encryption/key-rotations
+func (h *Handler) CreateKeyRotation(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	job, err := h.service.StartRotation(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "rotation_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetKeyRotation handles GET /v1/encryption/key-rotations/:id
+func (h *Handler) GetKeyRotation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Rotation ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	job, err := h.service.GetRotationJob(c.Request.Context(), tenantID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Key rotation not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,153 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Keystore wraps and unwraps tenant data keys with a master key
+type Keystore interface {
+	// ActiveKeyID returns the master key used for wrapping new data keys
+	ActiveKeyID() string
+
+	// Wrap encrypts a data key with the active master key
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+
+	// Unwrap decrypts a data key that was wrapped by the given master key
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeystore keeps master keys in process memory.
+// Keys are loaded from the environment:
+//
+//	ENCRYPTION_MASTER_KEY         base64 encoded 32 byte key used for wrapping
+//	ENCRYPTION_MASTER_KEY_ID      identifier stored alongside wrapped keys
+//	ENCRYPTION_RETIRED_MASTER_KEYS comma separated "id:base64" pairs still accepted for unwrapping
+type LocalKeystore struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewLocalKeystore creates a keystore from environment configuration
+func NewLocalKeystore() (*LocalKeystore, error) {
+	ks := &LocalKeystore{keys: make(map[string][]byte)}
+
+	activeID := os.Getenv("ENCRYPTION_MASTER_KEY_ID")
+	if activeID == "" {
+		activeID = "local-1"
+	}
+
+	encoded := os.Getenv("ENCRYPTION_MASTER_KEY")
+	if encoded == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("ENCRYPTION_MASTER_KEY is required in release mode")
+		}
+		// Development fallback so the API can start without extra setup.
+		log.Println("⚠️  ENCRYPTION_MASTER_KEY not set, using insecure development master key")
+		devKey := sha256.Sum256([]byte("mighty-eagle-dev-master-key"))
+		encoded = base64.StdEncoding.EncodeToString(devKey[:])
+	}
+
+	key, err := decodeMasterKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENCRYPTION_MASTER_KEY: %w", err)
+	}
+	ks.activeID = activeID
+	ks.keys[activeID] = key
+
+	if retired := os.Getenv("ENCRYPTION_RETIRED_MASTER_KEYS"); retired != "" {
+		for _, pair := range strings.Split(retired, ",") {
+			parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid ENCRYPTION_RETIRED_MASTER_KEYS entry %q", pair)
+			}
+			key, err := decodeMasterKey(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid retired master key %s: %w", parts[0], err)
+			}
+			ks.keys[parts[0]] = key
+		}
+	}
+
+	return ks, nil
+}
+
+// ActiveKeyID returns the master key used for wrapping new data keys
+func (k *LocalKeystore) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Wrap encrypts a data key with the active master key
+func (k *LocalKeystore) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return k.activeID, wrapped, nil
+}
+
+// Unwrap decrypts a data key that was wrapped by the given master key
+func (k *LocalKeystore) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("master key %s not available", keyID)
+	}
+	dataKey, err := open(key, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+func decodeMasterKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// seal encrypts plaintext with AES-256-GCM and prefixes the random nonce
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open reverses seal
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
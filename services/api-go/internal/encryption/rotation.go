@@ -0,0 +1,378 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sensitiveTable describes the encrypted columns of a table
+type sensitiveTable struct {
+	Name        string
+	TextColumns []string // Encrypted with Encrypt
+	JSONColumns []string // Encrypted with EncryptJSON
+	IndexColumn string   // Blind index recomputed from subject_id when missing
+}
+
+// sensitiveTables lists every table holding fields encrypted by this package.
+// The re-encryption job walks them in order.
+var sensitiveTables = []sensitiveTable{
+	{
+		Name:        "persona_verifications",
+		TextColumns: []string{"subject_id"},
+		JSONColumns: []string{"verification_data"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "event_log",
+		TextColumns: []string{"subject_id", "ip_address"},
+		JSONColumns: []string{"metadata"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "reputation_scores",
+		TextColumns: []string{"subject_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "reputation_signals",
+		TextColumns: []string{"subject_id", "source_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "reputation_watches",
+		TextColumns: []string{"subject_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "signal_appeals",
+		TextColumns: []string{"subject_id", "reason"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "trust_anomaly_members",
+		TextColumns: []string{"subject_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "reputation_federation_links",
+		TextColumns: []string{"subject_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "consensual_links",
+		TextColumns: []string{"initiator_id", "recipient_id"},
+	},
+	{
+		Name:        "trust_badges",
+		TextColumns: []string{"subject_id"},
+		IndexColumn: "subject_index",
+	},
+	{
+		Name:        "subject_erasure_jobs",
+		TextColumns: []string{"subject_id"},
+	},
+	{
+		Name:        "subject_access_exports",
+		TextColumns: []string{"subject_id"},
+		JSONColumns: []string{"bundle"},
+	},
+	{
+		Name:        "subject_pseudonyms",
+		TextColumns: []string{"external_id"},
+	},
+}
+
+const (
+	// rotationBatchSize is the number of rows re-encrypted per transaction
+	rotationBatchSize = 500
+
+	// rotationStaleAfter is how long a processing job may go without a heartbeat
+	rotationStaleAfter = 5 * time.Minute
+)
+
+// StartRotation creates a new data key version for the tenant and queues a
+// job that re-encrypts existing rows with it. Data keys wrapped by a retired
+// master key are re-wrapped with the active one at the same time.
+func (s *Service) StartRotation(ctx context.Context, tenantID uuid.UUID) (*models.KeyRotationJob, error) {
+	var running int64
+	if err := s.db.WithContext(ctx).Model(&models.KeyRotationJob{}).
+		Where("tenant_id = ? AND status IN ?", tenantID, []string{"pending", "processing"}).
+		Count(&running).Error; err != nil {
+		return nil, fmt.Errorf("failed to check running rotations: %w", err)
+	}
+	if running > 0 {
+		return nil, fmt.Errorf("a key rotation is already in progress")
+	}
+
+	keys, err := s.tenantKeys(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion := keys.active
+
+	if err := s.rewrapDataKeys(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.createDataKey(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, fmt.Errorf("concurrent key creation, please retry")
+	}
+	s.forget(tenantID)
+
+	job := models.KeyRotationJob{
+		TenantID:    tenantID,
+		FromVersion: fromVersion,
+		ToVersion:   created.Version,
+		Status:      "pending",
+		CreatedAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rotation job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// GetRotationJob retrieves a rotation job for a tenant
+func (s *Service) GetRotationJob(ctx context.Context, tenantID uuid.UUID, jobID uuid.UUID) (*models.KeyRotationJob, error) {
+	var job models.KeyRotationJob
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", jobID, tenantID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// rewrapDataKeys re-wraps data keys whose master key is no longer active
+func (s *Service) rewrapDataKeys(ctx context.Context, tenantID uuid.UUID) error {
+	var rows []models.TenantDataKey
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND master_key_id <> ?", tenantID, s.keystore.ActiveKeyID()).
+		Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load data keys: %w", err)
+	}
+
+	for _, row := range rows {
+		dataKey, err := s.keystore.Unwrap(ctx, row.MasterKeyID, row.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("data key version %d: %w", row.Version, err)
+		}
+		masterKeyID, wrapped, err := s.keystore.Wrap(ctx, dataKey)
+		if err != nil {
+			return err
+		}
+		if err := s.db.WithContext(ctx).Model(&models.TenantDataKey{}).Where("id = ?", row.ID).Updates(map[string]interface{}{
+			"wrapped_key":   wrapped,
+			"master_key_id": masterKeyID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to re-wrap data key version %d: %w", row.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// Worker processes pending key rotation jobs
+func (s *Service) Worker(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processPendingRotations(ctx)
+		}
+	}
+}
+
+func (s *Service) processPendingRotations(ctx context.Context) {
+	// Jobs whose heartbeat stopped were abandoned by a restarted replica and can be resumed
+	staleBefore := time.Now().Add(-rotationStaleAfter)
+
+	var jobs []models.KeyRotationJob
+	if err := s.db.WithContext(ctx).
+		Where("status = ? OR (status = ? AND heartbeat_at < ?)", "pending", "processing", staleBefore).
+		Order("created_at ASC").Limit(5).Find(&jobs).Error; err != nil {
+		log.Printf("Error fetching key rotation jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		// Claim the job so only one replica processes it at a time
+		now := time.Now()
+		claim := s.db.WithContext(ctx).Model(&models.KeyRotationJob{}).
+			Where("id = ? AND status = ? AND heartbeat_at IS NOT DISTINCT FROM ?", job.ID, job.Status, job.HeartbeatAt).
+			Updates(map[string]interface{}{"status": "processing", "started_at": now, "heartbeat_at": now})
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		if err := s.runRotation(ctx, &job); err != nil {
+			msg := err.Error()
+			s.db.Model(&models.KeyRotationJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"status":        "failed",
+				"error_message": msg,
+			})
+			log.Printf("Key rotation %s failed: %v", job.ID, err)
+		}
+	}
+}
+
+// runRotation re-encrypts every sensitive row of the tenant with the job's target key
+func (s *Service) runRotation(ctx context.Context, job *models.KeyRotationJob) error {
+	startTable := 0
+	if job.CurrentTable != nil {
+		for i, t := range sensitiveTables {
+			if t.Name == *job.CurrentTable {
+				startTable = i
+			}
+		}
+	}
+
+	for i := startTable; i < len(sensitiveTables); i++ {
+		table := sensitiveTables[i]
+		lastID := uuid.Nil
+		if job.CurrentTable != nil && *job.CurrentTable == table.Name && job.LastID != nil {
+			lastID = *job.LastID
+		}
+
+		for {
+			processed, nextID, err := s.reencryptBatch(ctx, job, table, lastID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", table.Name, err)
+			}
+			if processed == 0 {
+				break
+			}
+			lastID = nextID
+			job.RowsReencrypted += processed
+
+			tableName := table.Name
+			if err := s.db.WithContext(ctx).Model(&models.KeyRotationJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"current_table":    tableName,
+				"last_id":          lastID,
+				"rows_reencrypted": job.RowsReencrypted,
+				"heartbeat_at":     time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to checkpoint rotation: %w", err)
+			}
+		}
+	}
+
+	// Older data keys stay available for decryption but are no longer used for writes
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.TenantDataKey{}).
+		Where("tenant_id = ? AND version < ? AND status = ?", job.TenantID, job.ToVersion, "active").
+		Updates(map[string]interface{}{"status": "retired", "retired_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to retire old data keys: %w", err)
+	}
+	s.forget(job.TenantID)
+
+	return s.db.WithContext(ctx).Model(&models.KeyRotationJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":       "completed",
+		"completed_at": now,
+	}).Error
+}
+
+// reencryptBatch re-encrypts one batch of rows ordered by id
+func (s *Service) reencryptBatch(ctx context.Context, job *models.KeyRotationJob, table sensitiveTable, afterID uuid.UUID) (int, uuid.UUID, error) {
+	var rows []map[string]interface{}
+	if err := s.db.WithContext(ctx).Table(table.Name).
+		Where("tenant_id = ? AND id > ?", job.TenantID, afterID).
+		Order("id ASC").
+		Limit(rotationBatchSize).
+		Find(&rows).Error; err != nil {
+		return 0, afterID, fmt.Errorf("failed to load rows: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, afterID, nil
+	}
+
+	lastID := afterID
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			id, err := uuid.Parse(fmt.Sprint(row["id"]))
+			if err != nil {
+				return fmt.Errorf("unexpected id %v: %w", row["id"], err)
+			}
+			lastID = id
+
+			updates := make(map[string]interface{})
+			for _, column := range table.TextColumns {
+				value, ok := row[column].(string)
+				if !ok || !s.needsReencryption(value, job.ToVersion) {
+					continue
+				}
+				plaintext, err := s.Decrypt(ctx, job.TenantID, value)
+				if err != nil {
+					return fmt.Errorf("row %s column %s: %w", id, column, err)
+				}
+				encrypted, err := s.Encrypt(ctx, job.TenantID, plaintext)
+				if err != nil {
+					return err
+				}
+				updates[column] = encrypted
+				// Legacy plaintext rows have no blind index yet
+				if column == "subject_id" && table.IndexColumn != "" {
+					updates[table.IndexColumn] = s.BlindIndex(job.TenantID, plaintext)
+				}
+			}
+			for _, column := range table.JSONColumns {
+				value, ok := row[column].(string)
+				if !ok {
+					continue
+				}
+				inner, wrapped := unwrapJSONEnvelope(value)
+				if wrapped && !s.needsReencryption(inner, job.ToVersion) {
+					continue
+				}
+				plaintext, err := s.DecryptJSON(ctx, job.TenantID, value)
+				if err != nil {
+					return fmt.Errorf("row %s column %s: %w", id, column, err)
+				}
+				encrypted, err := s.EncryptJSON(ctx, job.TenantID, plaintext)
+				if err != nil {
+					return err
+				}
+				updates[column] = encrypted
+			}
+
+			if len(updates) == 0 {
+				continue
+			}
+			if err := tx.Table(table.Name).Where("id = ?", id).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update row %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, afterID, err
+	}
+
+	return len(rows), lastID, nil
+}
+
+// needsReencryption reports whether a text value is plaintext or sealed with an older key
+func (s *Service) needsReencryption(value string, targetVersion int) bool {
+	if !IsEncrypted(value) {
+		return value != ""
+	}
+	return !hasKeyVersion(value, targetVersion)
+}
+
+func hasKeyVersion(value string, version int) bool {
+	return strings.HasPrefix(value, fmt.Sprintf("%s%d:", envelopePrefix, version))
+}
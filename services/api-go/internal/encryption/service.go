@@ -0,0 +1,330 @@
+package encryption
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// envelopePrefix marks a value encrypted by this package.
+// Format: "enc:v1:{data_key_version}:{base64(nonce || ciphertext)}"
+const envelopePrefix = "enc:v1:"
+
+// jsonEnvelopeField is the key used when an encrypted value is stored in a jsonb column
+const jsonEnvelopeField = "_enc"
+
+// Service provides transparent field-level encryption with per-tenant data keys
+type Service struct {
+	db       *gorm.DB
+	keystore Keystore
+	indexKey []byte
+
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*tenantKeys
+}
+
+// tenantKeys caches unwrapped data keys for a tenant
+type tenantKeys struct {
+	active int
+	byVer  map[int][]byte
+}
+
+// NewService creates a new encryption service
+func NewService(db *gorm.DB, keystore Keystore) (*Service, error) {
+	// The blind index key is deliberately separate from the master key so that
+	// rotating data or master keys never changes index values.
+	indexKey := []byte(os.Getenv("ENCRYPTION_INDEX_KEY"))
+	if len(indexKey) == 0 {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("ENCRYPTION_INDEX_KEY is required in release mode")
+		}
+		log.Println("⚠️  ENCRYPTION_INDEX_KEY not set, using insecure development index key")
+		indexKey = []byte("mighty-eagle-dev-index-key")
+	}
+
+	return &Service{
+		db:       db,
+		keystore: keystore,
+		indexKey: indexKey,
+		keys:     make(map[uuid.UUID]*tenantKeys),
+	}, nil
+}
+
+// IsEncrypted reports whether a value carries an encryption envelope
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// Encrypt encrypts a plaintext value with the tenant's active data key
+func (s *Service) Encrypt(ctx context.Context, tenantID uuid.UUID, plaintext string) (string, error) {
+	version, key, err := s.activeKey(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(key, []byte(plaintext), tenantID[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt value: %w", err)
+	}
+
+	return fmt.Sprintf("%s%d:%s", envelopePrefix, version, base64.RawURLEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt.
+// Values without an envelope are returned unchanged so legacy plaintext rows stay readable.
+func (s *Service) Decrypt(ctx context.Context, tenantID uuid.UUID, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, envelopePrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed encryption envelope")
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("malformed key version in envelope: %w", err)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed ciphertext in envelope: %w", err)
+	}
+
+	key, err := s.keyVersion(ctx, tenantID, version)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(key, sealed, tenantID[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptPtr encrypts an optional value
+func (s *Service) EncryptPtr(ctx context.Context, tenantID uuid.UUID, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	encrypted, err := s.Encrypt(ctx, tenantID, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// DecryptPtr decrypts an optional value
+func (s *Service) DecryptPtr(ctx context.Context, tenantID uuid.UUID, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	decrypted, err := s.Decrypt(ctx, tenantID, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
+}
+
+// EncryptJSON encrypts a JSON document and wraps it so it remains valid jsonb
+func (s *Service) EncryptJSON(ctx context.Context, tenantID uuid.UUID, document string) (string, error) {
+	encrypted, err := s.Encrypt(ctx, tenantID, document)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := json.Marshal(map[string]string{jsonEnvelopeField: encrypted})
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap encrypted document: %w", err)
+	}
+	return string(wrapped), nil
+}
+
+// DecryptJSON reverses EncryptJSON. Plain JSON documents are returned unchanged.
+func (s *Service) DecryptJSON(ctx context.Context, tenantID uuid.UUID, document string) (string, error) {
+	encrypted, ok := unwrapJSONEnvelope(document)
+	if !ok {
+		return document, nil
+	}
+	return s.Decrypt(ctx, tenantID, encrypted)
+}
+
+// IsEncryptedJSON reports whether a jsonb document is an encryption envelope
+func IsEncryptedJSON(document string) bool {
+	_, ok := unwrapJSONEnvelope(document)
+	return ok
+}
+
+func unwrapJSONEnvelope(document string) (string, bool) {
+	if !strings.Contains(document, jsonEnvelopeField) {
+		return "", false
+	}
+	var wrapper map[string]string
+	if err := json.Unmarshal([]byte(document), &wrapper); err != nil || len(wrapper) != 1 {
+		return "", false
+	}
+	encrypted, ok := wrapper[jsonEnvelopeField]
+	if !ok || !IsEncrypted(encrypted) {
+		return "", false
+	}
+	return encrypted, true
+}
+
+// BlindIndex computes a deterministic, tenant-scoped keyed hash of a value.
+// It allows equality lookups (e.g. by subject_id) on encrypted columns.
+func (s *Service) BlindIndex(tenantID uuid.UUID, value string) string {
+	tenantMAC := hmac.New(sha256.New, s.indexKey)
+	tenantMAC.Write(tenantID[:])
+	tenantKey := tenantMAC.Sum(nil)
+
+	h := hmac.New(sha256.New, tenantKey)
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// BlindIndexPtr computes a blind index for an optional value
+func (s *Service) BlindIndexPtr(tenantID uuid.UUID, value *string) *string {
+	if value == nil {
+		return nil
+	}
+	index := s.BlindIndex(tenantID, *value)
+	return &index
+}
+
+// activeKey returns the tenant's active data key, creating the first one on demand
+func (s *Service) activeKey(ctx context.Context, tenantID uuid.UUID) (int, []byte, error) {
+	keys, err := s.tenantKeys(ctx, tenantID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if keys.active == 0 {
+		if _, err := s.createDataKey(ctx, tenantID); err != nil {
+			return 0, nil, err
+		}
+		s.forget(tenantID)
+		if keys, err = s.tenantKeys(ctx, tenantID); err != nil {
+			return 0, nil, err
+		}
+	}
+	return keys.active, keys.byVer[keys.active], nil
+}
+
+// keyVersion returns a specific data key version for decryption
+func (s *Service) keyVersion(ctx context.Context, tenantID uuid.UUID, version int) ([]byte, error) {
+	keys, err := s.tenantKeys(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := keys.byVer[version]; ok {
+		return key, nil
+	}
+
+	// Another replica may have rotated; reload once before giving up.
+	s.forget(tenantID)
+	if keys, err = s.tenantKeys(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	key, ok := keys.byVer[version]
+	if !ok {
+		return nil, fmt.Errorf("data key version %d not found for tenant", version)
+	}
+	return key, nil
+}
+
+// tenantKeys loads and unwraps all data keys for a tenant
+func (s *Service) tenantKeys(ctx context.Context, tenantID uuid.UUID) (*tenantKeys, error) {
+	s.mu.RLock()
+	cached, ok := s.keys[tenantID]
+	s.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	var rows []models.TenantDataKey
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load data keys: %w", err)
+	}
+
+	keys := &tenantKeys{byVer: make(map[int][]byte)}
+	for _, row := range rows {
+		key, err := s.keystore.Unwrap(ctx, row.MasterKeyID, row.WrappedKey)
+		if err != nil {
+			return nil, fmt.Errorf("data key version %d: %w", row.Version, err)
+		}
+		keys.byVer[row.Version] = key
+		if row.Status == "active" && row.Version > keys.active {
+			keys.active = row.Version
+		}
+	}
+
+	s.mu.Lock()
+	s.keys[tenantID] = keys
+	s.mu.Unlock()
+	return keys, nil
+}
+
+// forget drops cached keys for a tenant
+func (s *Service) forget(tenantID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.keys, tenantID)
+	s.mu.Unlock()
+}
+
+// createDataKey generates and stores a new data key version for a tenant
+func (s *Service) createDataKey(ctx context.Context, tenantID uuid.UUID) (*models.TenantDataKey, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	masterKeyID, wrapped, err := s.keystore.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var created models.TenantDataKey
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int
+		if err := tx.Model(&models.TenantDataKey{}).
+			Where("tenant_id = ?", tenantID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&current).Error; err != nil {
+			return err
+		}
+
+		created = models.TenantDataKey{
+			TenantID:    tenantID,
+			Version:     current + 1,
+			WrappedKey:  wrapped,
+			MasterKeyID: masterKeyID,
+			Status:      "active",
+			CreatedAt:   time.Now(),
+		}
+		// The unique (tenant_id, version) constraint resolves races between replicas.
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		// A concurrent request may have created the first key; use theirs.
+		s.forget(tenantID)
+		if current, loadErr := s.tenantKeys(ctx, tenantID); loadErr == nil && current.active > 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to store data key: %w", err)
+	}
+
+	return &created, nil
+}
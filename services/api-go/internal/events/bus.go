@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a domain event, published once it is recorded in the audit log
+type Event struct {
+	ID           uuid.UUID
+	TenantID     uuid.UUID
+	Type         string
+	SubjectID    *string // Plaintext; events never leave the process
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	Metadata     map[string]interface{}
+	OccurredAt   time.Time
+}
+
+// Handler receives published events
+type Handler func(ctx context.Context, event Event)
+
+type subscription struct {
+	pattern string
+	handler Handler
+}
+
+// Bus is an in-process publish/subscribe bus for domain events.
+// Handlers run synchronously on the publisher's goroutine, so slow work
+// should be handed off to a worker.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for events matching pattern: an exact event
+// type ("persona.verified"), a namespace ("consent.*") or everything ("*")
+func (b *Bus) Subscribe(pattern string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{pattern: pattern, handler: handler})
+}
+
+// Publish delivers an event to every matching handler. A panicking handler
+// is logged and does not affect the others or the publisher.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if Match(sub.pattern, event.Type) {
+			deliver(ctx, sub.handler, event)
+		}
+	}
+}
+
+// Match reports whether an event type matches a subscription pattern
+func Match(pattern, eventType string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == eventType
+}
+
+func deliver(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler panicked on %s %s: %v", event.Type, event.ID, r)
+		}
+	}()
+	handler(ctx, event)
+}
@@ -0,0 +1,213 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Handler manages link and badge HTTP endpoints
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new links handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateLink handles POST /v1/links
+func (h *Handler) CreateLink(c *gin.Context) {
+	var input CreateLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	link, err := h.service.CreateLink(c.Request.Context(), tenantID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "link_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListLinks handles GET /v1/links?subject_id=
+func (h *Handler) ListLinks(c *gin.Context) {
+	subjectID := c.Query("subject_id")
+	if subjectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "subject_id is required",
+		})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	links, total, err := h.service.ListLinks(c.Request.Context(), tenantID, ListLinksInput{
+		SubjectID: subjectID,
+		Status:    c.Query("status"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"links":  links,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetLink handles GET /v1/links/:id
+func (h *Handler) GetLink(c *gin.Context) {
+	id, ok := parseLinkID(c)
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	link, err := h.service.GetLink(c.Request.Context(), tenantID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Link not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// AcceptLink handles POST /v1/links/:id/accept
+func (h *Handler) AcceptLink(c *gin.Context) {
+	h.respond(c, h.service.AcceptLink)
+}
+
+// ConfirmLink handles POST /v1/links/:id/confirm
+func (h *Handler) ConfirmLink(c *gin.Context) {
+	h.respond(c, h.service.ConfirmLink)
+}
+
+// DeclineLink handles POST /v1/links/:id/decline
+func (h *Handler) DeclineLink(c *gin.Context) {
+	id, ok := parseLinkID(c)
+	if !ok {
+		return
+	}
+
+	var input DeclineLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	link, err := h.service.DeclineLink(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		linkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// ListBadges handles GET /v1/subjects/:id/badges
+func (h *Handler) ListBadges(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	badges, err := h.service.ListBadges(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"badges": badges})
+}
+
+// respond handles the accept and confirm flows, which share a request shape
+func (h *Handler) respond(c *gin.Context, action func(context.Context, uuid.UUID, uuid.UUID, RespondLinkInput) (*models.ConsensualLink, error)) {
+	id, ok := parseLinkID(c)
+	if !ok {
+		return
+	}
+
+	var input RespondLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	link, err := action(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		linkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+func parseLinkID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Link ID must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// linkError maps service errors to responses
+func linkError(c *gin.Context, err error) {
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Link not found",
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "link_failed",
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,505 @@
+package links
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/persona"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultLinkTTL is how long a link waits for the other party
+const defaultLinkTTL = 7 * 24 * time.Hour
+
+// Service manages consensual links and the trust badges they award
+type Service struct {
+	db      *gorm.DB
+	audit   *audit.Logger
+	crypto  *encryption.Service
+	persona *persona.Service
+}
+
+// NewService creates a new links service
+func NewService(db *gorm.DB, audit *audit.Logger, crypto *encryption.Service, persona *persona.Service) *Service {
+	return &Service{
+		db:      db,
+		audit:   audit,
+		crypto:  crypto,
+		persona: persona,
+	}
+}
+
+// WorldIDSignature records the proof a party signed a link with
+type WorldIDSignature struct {
+	Signature     string    `json:"signature"`      // SHA-256 of the submitted proof
+	Timestamp     time.Time `json:"timestamp"`      // When the proof was verified
+	NullifierHash string    `json:"nullifier_hash"` // Tenant pseudonym of the nullifier
+}
+
+// ProofInput carries a party's proof of personhood
+type ProofInput struct {
+	Provider string                 `json:"provider"` // Defaults to worldid
+	Metadata map[string]interface{} `json:"metadata" binding:"required"`
+}
+
+// CreateLinkInput represents input for initiating a link
+type CreateLinkInput struct {
+	InitiatorID string      `json:"initiator_id" binding:"required"`
+	RecipientID string      `json:"recipient_id" binding:"required"`
+	EventID     *string     `json:"event_id"`
+	ExpiresAt   *time.Time  `json:"expires_at"`
+	Proof       *ProofInput `json:"proof"` // Optional; otherwise the initiator confirms after acceptance
+}
+
+// RespondLinkInput represents a party's proof when accepting or confirming a link
+type RespondLinkInput struct {
+	Proof ProofInput `json:"proof" binding:"required"`
+}
+
+// DeclineLinkInput represents input for declining a link
+type DeclineLinkInput struct {
+	Reason *string `json:"reason"`
+}
+
+// ListLinksInput represents filters for listing a subject's links
+type ListLinksInput struct {
+	SubjectID string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// CreateLink initiates a link from one subject to another
+func (s *Service) CreateLink(ctx context.Context, tenantID uuid.UUID, input CreateLinkInput) (*models.ConsensualLink, error) {
+	if input.InitiatorID == input.RecipientID {
+		return nil, fmt.Errorf("a subject cannot link with itself")
+	}
+	expiresAt := time.Now().Add(defaultLinkTTL)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("expires_at must be in the future")
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	initiator, err := s.crypto.Encrypt(ctx, tenantID, input.InitiatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt initiator: %w", err)
+	}
+	recipient, err := s.crypto.Encrypt(ctx, tenantID, input.RecipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt recipient: %w", err)
+	}
+
+	link := models.ConsensualLink{
+		ID:             uuid.New(),
+		TenantID:       tenantID,
+		InitiatorID:    initiator,
+		InitiatorIndex: s.crypto.BlindIndex(tenantID, input.InitiatorID),
+		RecipientID:    recipient,
+		RecipientIndex: s.crypto.BlindIndex(tenantID, input.RecipientID),
+		EventID:        input.EventID,
+		Status:         "pending",
+		ExpiresAt:      expiresAt,
+	}
+
+	if input.Proof != nil {
+		// The link ID is not known to the client yet, so this proof is not bound to it
+		signature, err := s.sign(ctx, tenantID, "", input.InitiatorID, *input.Proof)
+		if err != nil {
+			return nil, err
+		}
+		link.InitiatorSignature = &signature
+	}
+
+	if err := s.db.WithContext(ctx).Create(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create link: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "link.initiated",
+		SubjectID:    &input.InitiatorID,
+		ResourceType: stringPtr("consensual_link"),
+		ResourceID:   &link.ID,
+		Metadata: map[string]interface{}{
+			"event_id": input.EventID,
+			"signed":   link.InitiatorSignature != nil,
+		},
+	})
+
+	return s.open(ctx, &link)
+}
+
+// AcceptLink records the recipient's proof. The link completes at once if the
+// initiator already signed, otherwise it waits for ConfirmLink.
+func (s *Service) AcceptLink(ctx context.Context, tenantID uuid.UUID, linkID uuid.UUID, input RespondLinkInput) (*models.ConsensualLink, error) {
+	link, err := s.loadOpenLink(ctx, tenantID, linkID, "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	recipientID, err := s.crypto.Decrypt(ctx, tenantID, link.RecipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt recipient: %w", err)
+	}
+	signature, err := s.sign(ctx, tenantID, link.ID.String(), recipientID, input.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if err := samePerson(link.InitiatorSignature, signature); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":              "accepted",
+		"recipient_signature": signature,
+		"accepted_at":         now,
+	}
+	if err := s.transition(ctx, link, "pending", updates); err != nil {
+		return nil, err
+	}
+	link.Status = "accepted"
+	link.RecipientSignature = &signature
+	link.AcceptedAt = &now
+
+	if link.InitiatorSignature != nil {
+		return s.complete(ctx, link)
+	}
+	s.logTransition(ctx, link, "link.accepted")
+	return s.open(ctx, link)
+}
+
+// ConfirmLink records the initiator's proof on an accepted link and completes it
+func (s *Service) ConfirmLink(ctx context.Context, tenantID uuid.UUID, linkID uuid.UUID, input RespondLinkInput) (*models.ConsensualLink, error) {
+	link, err := s.loadOpenLink(ctx, tenantID, linkID, "accepted")
+	if err != nil {
+		return nil, err
+	}
+	if link.InitiatorSignature != nil {
+		return nil, fmt.Errorf("link already signed by the initiator")
+	}
+
+	initiatorID, err := s.crypto.Decrypt(ctx, tenantID, link.InitiatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt initiator: %w", err)
+	}
+	signature, err := s.sign(ctx, tenantID, link.ID.String(), initiatorID, input.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if err := samePerson(link.RecipientSignature, signature); err != nil {
+		return nil, err
+	}
+
+	if err := s.transition(ctx, link, "accepted", map[string]interface{}{"initiator_signature": signature}); err != nil {
+		return nil, err
+	}
+	link.InitiatorSignature = &signature
+
+	return s.complete(ctx, link)
+}
+
+// DeclineLink marks a pending link as declined by the recipient
+func (s *Service) DeclineLink(ctx context.Context, tenantID uuid.UUID, linkID uuid.UUID, input DeclineLinkInput) (*models.ConsensualLink, error) {
+	link, err := s.loadOpenLink(ctx, tenantID, linkID, "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.transition(ctx, link, "pending", map[string]interface{}{
+		"status":         "declined",
+		"decline_reason": input.Reason,
+		"declined_at":    now,
+	}); err != nil {
+		return nil, err
+	}
+	link.Status = "declined"
+	link.DeclineReason = input.Reason
+	link.DeclinedAt = &now
+
+	s.logTransition(ctx, link, "link.declined")
+	return s.open(ctx, link)
+}
+
+// GetLink retrieves a link by ID
+func (s *Service) GetLink(ctx context.Context, tenantID uuid.UUID, linkID uuid.UUID) (*models.ConsensualLink, error) {
+	var link models.ConsensualLink
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", linkID, tenantID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return s.open(ctx, &link)
+}
+
+// ListLinks returns links where the subject is either party, newest first
+func (s *Service) ListLinks(ctx context.Context, tenantID uuid.UUID, input ListLinksInput) ([]models.ConsensualLink, int64, error) {
+	subjectIndex := s.crypto.BlindIndex(tenantID, input.SubjectID)
+	query := s.db.WithContext(ctx).Model(&models.ConsensualLink{}).
+		Where("tenant_id = ? AND (initiator_index = ? OR recipient_index = ?)", tenantID, subjectIndex, subjectIndex)
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count links: %w", err)
+	}
+
+	if input.Limit <= 0 || input.Limit > 100 {
+		input.Limit = 100
+	}
+
+	var links []models.ConsensualLink
+	if err := query.Order("created_at DESC").Limit(input.Limit).Offset(input.Offset).Find(&links).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list links: %w", err)
+	}
+	for i := range links {
+		opened, err := s.open(ctx, &links[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		links[i] = *opened
+	}
+
+	return links, total, nil
+}
+
+// ListBadges returns the trust badges held by a subject
+func (s *Service) ListBadges(ctx context.Context, tenantID uuid.UUID, subjectID string) ([]models.TrustBadge, error) {
+	var badges []models.TrustBadge
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		Order("awarded_at DESC").
+		Find(&badges).Error; err != nil {
+		return nil, fmt.Errorf("failed to list badges: %w", err)
+	}
+	for i := range badges {
+		badges[i].SubjectID = subjectID
+	}
+	return badges, nil
+}
+
+// Worker expires links that were not completed in time
+func (s *Service) Worker(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireLinks(ctx)
+		}
+	}
+}
+
+func (s *Service) expireLinks(ctx context.Context) {
+	var expired []models.ConsensualLink
+	if err := s.db.WithContext(ctx).
+		Where("status IN ? AND expires_at < ?", []string{"pending", "accepted"}, time.Now()).
+		Limit(100).
+		Find(&expired).Error; err != nil {
+		log.Printf("Error fetching expired links: %v", err)
+		return
+	}
+
+	for i := range expired {
+		link := &expired[i]
+		if err := s.transition(ctx, link, link.Status, map[string]interface{}{"status": "expired"}); err != nil {
+			continue
+		}
+		link.Status = "expired"
+		s.logTransition(ctx, link, "link.expired")
+	}
+}
+
+// complete marks an accepted link completed and awards a badge to each party
+func (s *Service) complete(ctx context.Context, link *models.ConsensualLink) (*models.ConsensualLink, error) {
+	opened, err := s.open(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	initiatorID, recipientID := opened.InitiatorID, opened.RecipientID
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ConsensualLink{}).
+			Where("id = ? AND status = ?", link.ID, "accepted").
+			Updates(map[string]interface{}{"status": "completed", "completed_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to complete link: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("link is no longer awaiting completion")
+		}
+
+		// Badges carry the stored (encrypted) subject and only an index of the counterpart
+		parties := []struct{ subject, index, linkedIndex string }{
+			{link.InitiatorID, link.InitiatorIndex, link.RecipientIndex},
+			{link.RecipientID, link.RecipientIndex, link.InitiatorIndex},
+		}
+		for _, p := range parties {
+			linkedIndex := p.linkedIndex
+			if err := tx.Create(&models.TrustBadge{
+				TenantID:           link.TenantID,
+				LinkID:             link.ID,
+				SubjectID:          p.subject,
+				SubjectIndex:       p.index,
+				LinkedSubjectIndex: &linkedIndex,
+				EventContext:       link.EventID,
+				AwardedAt:          now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to award badge: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	opened.Status = "completed"
+	opened.CompletedAt = &now
+
+	// Badges feed the History component of both parties' scores; the events
+	// invalidate their cached reputation
+	for _, subjectID := range []string{initiatorID, recipientID} {
+		subjectID := subjectID
+		s.audit.LogEvent(ctx, audit.LogEventInput{
+			TenantID:     link.TenantID,
+			EventType:    "link.badge_awarded",
+			SubjectID:    &subjectID,
+			ResourceType: stringPtr("consensual_link"),
+			ResourceID:   &link.ID,
+			Metadata: map[string]interface{}{
+				"event_id": link.EventID,
+			},
+		})
+	}
+	s.logTransition(ctx, link, "link.completed")
+
+	return opened, nil
+}
+
+// sign verifies a party's proof and returns the signature JSON. A non-empty
+// signal replaces the proof's signal so it cannot be replayed on another link.
+func (s *Service) sign(ctx context.Context, tenantID uuid.UUID, signal string, subjectID string, proof ProofInput) (string, error) {
+	if proof.Provider == "" {
+		proof.Provider = "worldid"
+	}
+	metadata := make(map[string]interface{}, len(proof.Metadata)+1)
+	for k, v := range proof.Metadata {
+		metadata[k] = v
+	}
+	if signal != "" {
+		metadata["signal"] = signal
+	}
+
+	result, err := s.persona.VerifyProof(ctx, tenantID, persona.VerificationInput{
+		SubjectID: subjectID,
+		Provider:  proof.Provider,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Status != persona.StatusVerified {
+		return "", fmt.Errorf("proof rejected: %s", result.Error)
+	}
+
+	submitted, _ := json.Marshal(proof.Metadata["proof"])
+	digest := sha256.Sum256(submitted)
+	signature, _ := json.Marshal(WorldIDSignature{
+		Signature:     hex.EncodeToString(digest[:]),
+		Timestamp:     time.Now(),
+		NullifierHash: result.ProofHash,
+	})
+	return string(signature), nil
+}
+
+// loadOpenLink loads a link in the given status that has not expired
+func (s *Service) loadOpenLink(ctx context.Context, tenantID uuid.UUID, linkID uuid.UUID, status string) (*models.ConsensualLink, error) {
+	var link models.ConsensualLink
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", linkID, tenantID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	if link.Status != status {
+		return nil, fmt.Errorf("link is %s", link.Status)
+	}
+	if link.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("link has expired")
+	}
+	return &link, nil
+}
+
+// transition applies updates only if the link is still in the expected status
+func (s *Service) transition(ctx context.Context, link *models.ConsensualLink, from string, updates map[string]interface{}) error {
+	result := s.db.WithContext(ctx).Model(&models.ConsensualLink{}).
+		Where("id = ? AND status = ?", link.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update link: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("link was modified concurrently")
+	}
+	return nil
+}
+
+func (s *Service) logTransition(ctx context.Context, link *models.ConsensualLink, eventType string) {
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     link.TenantID,
+		EventType:    eventType,
+		ResourceType: stringPtr("consensual_link"),
+		ResourceID:   &link.ID,
+		Metadata: map[string]interface{}{
+			"event_id": link.EventID,
+		},
+	})
+}
+
+// open returns a copy of the link with party identifiers decrypted
+func (s *Service) open(ctx context.Context, link *models.ConsensualLink) (*models.ConsensualLink, error) {
+	opened := *link
+	initiator, err := s.crypto.Decrypt(ctx, link.TenantID, link.InitiatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt initiator: %w", err)
+	}
+	recipient, err := s.crypto.Decrypt(ctx, link.TenantID, link.RecipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt recipient: %w", err)
+	}
+	opened.InitiatorID = initiator
+	opened.RecipientID = recipient
+	return &opened, nil
+}
+
+// samePerson rejects a link signed twice by the same human
+func samePerson(existing *string, signature string) error {
+	if existing == nil {
+		return nil
+	}
+	var a, b WorldIDSignature
+	if json.Unmarshal([]byte(*existing), &a) != nil || json.Unmarshal([]byte(signature), &b) != nil {
+		return nil
+	}
+	if a.NullifierHash != "" && a.NullifierHash == b.NullifierHash {
+		return fmt.Errorf("both parties presented the same proof of personhood")
+	}
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
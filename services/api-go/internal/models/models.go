@@ -31,13 +31,20 @@ type EventLog struct {
 	EventType    string    `gorm:"not null" json:"event_type"`
 	EventVersion string    `gorm:"not null;default:'v1'" json:"event_version"`
 	ActorID      *string   `json:"actor_id,omitempty"`
-	SubjectID    *string   `json:"subject_id,omitempty"`
+	SubjectID    *string   `json:"subject_id,omitempty"` // Encrypted at rest
+	SubjectIndex *string   `json:"-"`                    // Blind index of SubjectID
+	MetadataIndex *string  `gorm:"type:text[]" json:"-"` // Blind indexes of Metadata's (path, value) pairs
 	ResourceType *string   `json:"resource_type,omitempty"`
 	ResourceID   *uuid.UUID `gorm:"type:uuid" json:"resource_id,omitempty"`
-	Metadata     string    `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"`
-	IPAddress    *string   `gorm:"type:inet" json:"ip_address,omitempty"`
+	Metadata     string    `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"` // Encrypted at rest
+	IPAddress    *string   `json:"ip_address,omitempty"`                             // Encrypted at rest
 	UserAgent    *string   `json:"user_agent,omitempty"`
 	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP;not null" json:"created_at"`
+	// Hash chain; nil for events logged before chaining was introduced
+	Sequence      *int64  `json:"sequence,omitempty"`
+	PrevHash      *string `json:"prev_hash,omitempty"`
+	Hash          *string `json:"hash,omitempty"`
+	ContentDigest *string `json:"-"` // Keyed digest of the personal fields
 }
 
 // TableName overrides the table name
@@ -45,16 +52,31 @@ func (EventLog) TableName() string {
 	return "event_log"
 }
 
+// EventLogChainHead is the latest link of a tenant's event hash chain
+type EventLogChainHead struct {
+	TenantID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"tenant_id"`
+	Sequence  int64     `gorm:"not null" json:"sequence"`
+	Hash      string    `gorm:"not null" json:"hash"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (EventLogChainHead) TableName() string {
+	return "event_log_chain_heads"
+}
+
 // PersonaVerification represents a persona verification record
 type PersonaVerification struct {
 	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	TenantID         uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
-	SubjectID        string     `gorm:"not null" json:"subject_id"`
+	SubjectID        string     `gorm:"not null" json:"subject_id"` // Encrypted at rest
+	SubjectIndex     string     `json:"-"`                         // Blind index of SubjectID
+	SubjectPseudonym string     `json:"subject_pseudonym"`         // Pairwise pseudonym of SubjectID
 	Provider         string     `gorm:"not null" json:"provider"`
 	Status           string     `gorm:"not null;default:'pending'" json:"status"` // pending, verified, failed, expired
 	ConfidenceScore  *float64   `gorm:"type:decimal(5,2)" json:"confidence_score,omitempty"`
-	VerificationData string     `gorm:"type:jsonb;not null;default:'{}'" json:"verification_data"`
-	ProofHash        *string    `json:"proof_hash,omitempty"`
+	VerificationData string     `gorm:"type:jsonb;not null;default:'{}'" json:"verification_data"` // Encrypted at rest
+	ProofHash        *string    `json:"proof_hash,omitempty"` // Pairwise pseudonym of the provider proof (e.g. nullifier)
 	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
 	VerifiedAt       *time.Time `json:"verified_at,omitempty"`
 	CreatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
@@ -92,16 +114,19 @@ func (ConsentToken) TableName() string {
 
 // ReputationScore represents a reputation score
 type ReputationScore struct {
-	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	TenantID     uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
-	SubjectID    string     `gorm:"not null" json:"subject_id"`
-	Score        float64    `gorm:"type:decimal(5,2);not null" json:"score"`
-	Level        string     `json:"level"`
-	Factors      string     `gorm:"type:jsonb;default:'{}'" json:"factors"`
-	CalculatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"calculated_at"`
-	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
-	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID      uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID     string     `gorm:"not null" json:"subject_id"` // Encrypted at rest
+	SubjectIndex  string     `json:"-"`                          // Blind index of SubjectID
+	Score         float64    `gorm:"type:decimal(5,2);not null" json:"score"`
+	Level         string     `json:"level"`
+	Factors       string     `gorm:"type:jsonb;default:'{}'" json:"factors"`
+	PolicyID      *uuid.UUID `gorm:"type:uuid" json:"policy_id,omitempty"` // Nil for the built-in default policy
+	PolicyVersion *int       `json:"policy_version,omitempty"`
+	CalculatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"calculated_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 // TableName overrides the table name
@@ -156,17 +181,30 @@ func (WebhookDelivery) TableName() string {
 type AuditExportJob struct {
 	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	TenantID       uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
-	Format         string     `gorm:"not null" json:"format"` // csv, json
-	Status         string     `gorm:"not null;default:'pending'" json:"status"` // pending, processing, completed, failed
+	Format         string     `gorm:"not null" json:"format"` // A registered export format: csv, json, ndjson, parquet, ocsf
+	Status         string     `gorm:"not null;default:'pending'" json:"status"` // pending, processing, completed, failed, cancelled
 	StartDate      time.Time  `gorm:"not null" json:"start_date"`
 	EndDate        time.Time  `gorm:"not null" json:"end_date"`
 	EventTypes     *string    `gorm:"type:text[]" json:"event_types,omitempty"`
-	DownloadURL    *string    `json:"download_url,omitempty"`
-	FileSizeByes   *int64     `json:"file_size_bytes,omitempty"`
+	StorageKey     *string    `json:"-"`                                   // Blob store key; cleared once the file is purged
+	Compression    *string    `json:"compression,omitempty"`               // gzip for large files
+	DownloadURL    *string    `gorm:"-" json:"download_url,omitempty"` // Signed on read
+	FileSizeBytes  *int64     `json:"file_size_bytes,omitempty"`
 	RecordCount    *int       `json:"record_count,omitempty"`
-	ErrorMessage   *string    `json:"error_message,omitempty"`
+	FileSHA256     *string    `gorm:"column:file_sha256" json:"file_sha256,omitempty"` // Digest of the file as downloaded
+	Encryption     *string    `json:"encryption,omitempty"`                             // age or openpgp when encrypted to the tenant's key
+	Manifest       *string    `json:"manifest,omitempty"`                               // Signed JSON, verbatim
+	ManifestSignature *string `gorm:"type:jsonb" json:"manifest_signature,omitempty"`
+	ErrorMessage   *string    `json:"error_message,omitempty"` // Last failure; the job may still be retried
 	Parameters     string     `gorm:"type:jsonb" json:"parameters,omitempty"`
+	ProgressPercent int       `gorm:"default:0" json:"progress_percent"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts    int        `gorm:"default:5" json:"-"`
+	RunAfter       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"-"` // Next attempt is due; delayed by retry backoff
+	LockedBy       *string    `json:"-"`                                   // Runner holding the lease
+	HeartbeatAt    *time.Time `json:"-"`
 	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
 	CompletedAt    *time.Time `json:"completed_at,omitempty"`
 	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
 }
@@ -176,6 +214,21 @@ func (AuditExportJob) TableName() string {
 	return "audit_export_jobs"
 }
 
+// TenantExportKey is the public key a tenant's audit exports are encrypted to
+type TenantExportKey struct {
+	TenantID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"tenant_id"`
+	KeyType     string    `gorm:"not null" json:"key_type"` // age, openpgp
+	PublicKey   string    `gorm:"not null" json:"public_key"`
+	Fingerprint string    `gorm:"not null" json:"fingerprint"` // The age recipient, or the OpenPGP key fingerprint
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (TenantExportKey) TableName() string {
+	return "tenant_export_keys"
+}
+
 // Subscription represents a tenant's billing subscription
 type Subscription struct {
 	ID                   uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
@@ -215,3 +268,387 @@ type UsageMetric struct {
 func (UsageMetric) TableName() string {
 	return "usage_metrics"
 }
+
+
+// TenantDataKey represents a per-tenant data encryption key wrapped by a master key
+type TenantDataKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	Version     int        `gorm:"not null" json:"version"`
+	WrappedKey  []byte     `gorm:"not null" json:"-"` // Never exposed
+	MasterKeyID string     `gorm:"not null" json:"master_key_id"`
+	Status      string     `gorm:"not null;default:'active'" json:"status"` // active, retired
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (TenantDataKey) TableName() string {
+	return "tenant_data_keys"
+}
+
+// KeyRotationJob represents a background re-encryption run after a data key rotation
+type KeyRotationJob struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID        uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	FromVersion     int        `gorm:"not null" json:"from_version"`
+	ToVersion       int        `gorm:"not null" json:"to_version"`
+	Status          string     `gorm:"not null;default:'pending'" json:"status"` // pending, processing, completed, failed
+	CurrentTable    *string    `json:"current_table,omitempty"`
+	LastID          *uuid.UUID `gorm:"type:uuid" json:"-"`
+	RowsReencrypted int        `gorm:"default:0" json:"rows_reencrypted"`
+	ErrorMessage    *string    `json:"error_message,omitempty"`
+	CreatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	HeartbeatAt     *time.Time `json:"-"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (KeyRotationJob) TableName() string {
+	return "key_rotation_jobs"
+}
+
+// SubjectErasureJob represents an asynchronous right-to-be-forgotten request
+type SubjectErasureJob struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID     uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID    *string    `json:"-"` // Encrypted, cleared once the job completes
+	SubjectIndex string     `gorm:"not null" json:"-"`
+	RequestedBy  *string    `json:"requested_by,omitempty"`
+	Status       string     `gorm:"not null;default:'pending'" json:"status"` // pending, processing, completed, failed
+	Summary      string     `gorm:"type:jsonb;default:'{}'" json:"summary"`
+	Certificate  *string    `gorm:"type:jsonb" json:"certificate,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"` // Reason the last attempt failed
+	Attempts     int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts  int        `gorm:"default:5" json:"-"`
+	RunAfter     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"-"` // Next attempt is due; delayed by retry backoff
+	LockedBy     *string    `json:"-"`                                  // Runner holding the lease
+	HeartbeatAt  *time.Time `json:"-"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (SubjectErasureJob) TableName() string {
+	return "subject_erasure_jobs"
+}
+
+// ErasureTombstone records a row that was redacted or removed by a subject erasure
+type ErasureTombstone struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID      uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	ErasureJobID  uuid.UUID `gorm:"type:uuid;not null" json:"erasure_job_id"`
+	SourceTable   string    `gorm:"not null" json:"source_table"`
+	RecordID      uuid.UUID `gorm:"type:uuid;not null" json:"record_id"`
+	Action        string    `gorm:"not null" json:"action"` // redacted, deleted
+	ContentDigest string    `gorm:"not null" json:"content_digest"`
+	ErasedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"erased_at"`
+}
+
+// TableName overrides the table name
+func (ErasureTombstone) TableName() string {
+	return "erasure_tombstones"
+}
+
+// SubjectAccessExport represents an asynchronous data subject access request export
+type SubjectAccessExport struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID      uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID     string     `gorm:"not null" json:"-"` // Encrypted at rest
+	SubjectIndex  string     `gorm:"not null" json:"-"`
+	Status        string     `gorm:"not null;default:'pending'" json:"status"` // pending, processing, completed, failed
+	Bundle        *string    `gorm:"type:jsonb" json:"-"`                      // Encrypted at rest
+	Manifest      *string    `gorm:"type:jsonb" json:"manifest,omitempty"`     // Signed manifest
+	RecordCount   *int       `json:"record_count,omitempty"`
+	FileSizeBytes *int64     `json:"file_size_bytes,omitempty"`
+	DownloadURL   *string    `gorm:"-" json:"download_url,omitempty"` // Signed on read
+	ErrorMessage  *string    `json:"error_message,omitempty"`         // Reason the last attempt failed
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"-"`
+	RunAfter      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"-"` // Next attempt is due; delayed by retry backoff
+	LockedBy      *string    `json:"-"`                                  // Runner holding the lease
+	HeartbeatAt   *time.Time `json:"-"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (SubjectAccessExport) TableName() string {
+	return "subject_access_exports"
+}
+
+// SubjectPseudonym maps a pairwise pseudonym to the identifier it was derived from
+type SubjectPseudonym struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"-"`
+	TenantID      uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	Pseudonym     string    `gorm:"not null" json:"pseudonym"`
+	Namespace     string    `gorm:"not null" json:"namespace"`   // subject, or the provider for proof identifiers
+	ExternalID    string    `gorm:"not null" json:"external_id"` // Encrypted at rest
+	ExternalIndex string    `gorm:"not null" json:"-"`           // Blind index of ExternalID
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (SubjectPseudonym) TableName() string {
+	return "subject_pseudonyms"
+}
+
+// ReputationSignal represents a positive or negative event feeding a subject's reputation
+type ReputationSignal struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID       uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID      string     `gorm:"not null" json:"subject_id"`  // Encrypted at rest
+	SubjectIndex   string     `gorm:"not null" json:"-"`           // Blind index of SubjectID
+	SignalType     string     `gorm:"not null" json:"signal_type"` // event_attended, no_show, report_upheld, dispute_resolved, endorsement
+	Weight         int        `gorm:"not null" json:"weight"`
+	SourceType     string     `gorm:"not null" json:"source_type"` // tenant, subject, moderator, system
+	SourceID       *string    `json:"source_id,omitempty"`         // Encrypted at rest
+	SourceIndex    *string    `json:"-"`                           // Blind index of SourceID
+	IdempotencyKey string     `gorm:"not null" json:"idempotency_key"`
+	Metadata       string     `gorm:"type:jsonb;default:'{}'" json:"metadata"`
+	OccurredAt     time.Time  `gorm:"not null" json:"occurred_at"`
+	Status         string     `gorm:"default:'active'" json:"status"` // active, overturned (dropped from scoring on appeal)
+	OverturnedAt   *time.Time `json:"overturned_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ReputationSignal) TableName() string {
+	return "reputation_signals"
+}
+
+// ConsensualLink represents a mutual, proof-backed connection between two subjects
+type ConsensualLink struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID           uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	InitiatorID        string     `gorm:"not null" json:"initiator_id"` // Encrypted at rest
+	InitiatorIndex     string     `gorm:"not null" json:"-"`
+	RecipientID        string     `gorm:"not null" json:"recipient_id"` // Encrypted at rest
+	RecipientIndex     string     `gorm:"not null" json:"-"`
+	EventID            *string    `json:"event_id,omitempty"`
+	Status             string     `gorm:"not null;default:'pending'" json:"status"` // pending, accepted, completed, expired, declined
+	InitiatorSignature *string    `gorm:"type:jsonb" json:"initiator_signature,omitempty"`
+	RecipientSignature *string    `gorm:"type:jsonb" json:"recipient_signature,omitempty"`
+	DeclineReason      *string    `json:"decline_reason,omitempty"`
+	ExpiresAt          time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt         *time.Time `json:"accepted_at,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	DeclinedAt         *time.Time `json:"declined_at,omitempty"`
+	CreatedAt          time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ConsensualLink) TableName() string {
+	return "consensual_links"
+}
+
+// TrustBadge is an anonymous badge awarded to each party of a completed link
+type TrustBadge struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID           uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	LinkID             uuid.UUID `gorm:"type:uuid;not null" json:"link_id"`
+	SubjectID          string    `gorm:"not null" json:"subject_id"` // Encrypted at rest
+	SubjectIndex       string    `gorm:"not null" json:"-"`
+	LinkedSubjectIndex *string   `json:"-"` // Counterpart, never exposed
+	EventContext       *string   `json:"event_context,omitempty"`
+	AwardedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"awarded_at"`
+}
+
+// TableName overrides the table name
+func (TrustBadge) TableName() string {
+	return "trust_badges"
+}
+
+// ScoringPolicy is a tenant-managed reputation scoring configuration
+type ScoringPolicy struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID      uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	Name          string    `gorm:"not null" json:"name"`
+	Description   *string   `json:"description,omitempty"`
+	LatestVersion int       `gorm:"not null;default:1" json:"latest_version"`
+	ActiveVersion *int      `json:"active_version,omitempty"` // Version used for scoring while the policy is active
+	IsActive      bool      `gorm:"default:false" json:"is_active"`
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ScoringPolicy) TableName() string {
+	return "scoring_policies"
+}
+
+// ScoringPolicyVersion is an immutable revision of a scoring policy
+type ScoringPolicyVersion struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	PolicyID  uuid.UUID `gorm:"type:uuid;not null" json:"policy_id"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	Version   int       `gorm:"not null" json:"version"`
+	Config    string    `gorm:"type:jsonb;not null" json:"config"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ScoringPolicyVersion) TableName() string {
+	return "scoring_policy_versions"
+}
+
+// ScoringPolicyActivation records a policy version becoming the tenant's active one
+type ScoringPolicyActivation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID    uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	PolicyID    uuid.UUID `gorm:"type:uuid;not null" json:"policy_id"`
+	Version     int       `gorm:"not null" json:"version"`
+	ActivatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"activated_at"`
+}
+
+// TableName overrides the table name
+func (ScoringPolicyActivation) TableName() string {
+	return "scoring_policy_activations"
+}
+
+// SignalAppeal is a subject's challenge of a negative reputation signal
+type SignalAppeal struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID       uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	SignalID       uuid.UUID  `gorm:"type:uuid;not null" json:"signal_id"`
+	SubjectID      string     `gorm:"not null" json:"subject_id"`            // Encrypted at rest
+	SubjectIndex   string     `gorm:"not null" json:"-"`                     // Blind index of SubjectID
+	Status         string     `gorm:"not null;default:'open'" json:"status"` // open, upheld, overturned
+	Reason         string     `gorm:"not null" json:"reason"`                // Encrypted at rest
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	ResolvedBy     *string    `json:"resolved_by,omitempty"` // Moderator identifier from the tenant's system
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (SignalAppeal) TableName() string {
+	return "signal_appeals"
+}
+
+// ReputationWatch notifies a tenant when scores cross a threshold
+type ReputationWatch struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID    *string   `json:"subject_id"` // Encrypted at rest; nil watches every subject
+	SubjectIndex *string   `json:"-"`          // Blind index of SubjectID
+	Threshold    float64   `gorm:"type:decimal(5,2);not null" json:"threshold"`
+	Direction    string    `gorm:"not null" json:"direction"` // below, above, both
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ReputationWatch) TableName() string {
+	return "reputation_watches"
+}
+
+// TrustAnomaly is a suspicious cluster in the endorsement and link graph.
+// While open, edges between its members count for less in scoring.
+type TrustAnomaly struct {
+	ID          uuid.UUID            `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID    uuid.UUID            `gorm:"type:uuid;not null" json:"tenant_id"`
+	Kind        string               `gorm:"not null" json:"kind"`                  // reciprocal_ring, sock_puppets, shared_nullifier
+	Fingerprint string               `gorm:"not null" json:"-"`                     // Digest of kind and members, so a cluster is raised once
+	Status      string               `gorm:"not null;default:'open'" json:"status"` // open, dismissed
+	Dampening   float64              `gorm:"type:decimal(4,3);not null" json:"dampening"`
+	Evidence    string               `gorm:"type:jsonb;not null;default:'{}'" json:"evidence"`
+	MemberCount int                  `gorm:"not null" json:"member_count"`
+	Members     []TrustAnomalyMember `gorm:"foreignKey:AnomalyID" json:"members,omitempty"`
+	DetectedAt  time.Time            `gorm:"not null" json:"detected_at"`
+	ResolvedBy  *string              `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time           `json:"resolved_at,omitempty"`
+	Note        *string              `json:"note,omitempty"`
+	CreatedAt   time.Time            `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time            `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (TrustAnomaly) TableName() string {
+	return "trust_anomalies"
+}
+
+// TrustAnomalyMember is a subject involved in a trust anomaly
+type TrustAnomalyMember struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"-"`
+	AnomalyID    uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	SubjectID    string    `gorm:"not null" json:"subject_id"` // Encrypted at rest
+	SubjectIndex string    `gorm:"not null" json:"-"`
+	Role         string    `gorm:"not null" json:"role"` // member, target, source
+}
+
+// TableName overrides the table name
+func (TrustAnomalyMember) TableName() string {
+	return "trust_anomaly_members"
+}
+
+// TrustGraphCursor records how far the trust graph analyzer has read a tenant's edges
+type TrustGraphCursor struct {
+	TenantID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"tenant_id"`
+	ProcessedUntil time.Time `gorm:"not null" json:"processed_until"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (TrustGraphCursor) TableName() string {
+	return "trust_graph_cursors"
+}
+
+// ReputationFederationLink lets a tenant read a subject's reputation at
+// another tenant, under the subject's federation consent held by that tenant
+type ReputationFederationLink struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID           uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	SubjectID          string    `gorm:"not null" json:"subject_id"` // Encrypted at rest
+	SubjectIndex       string    `gorm:"not null" json:"-"`
+	SourceTenantID     uuid.UUID `gorm:"type:uuid;not null" json:"source_tenant_id"`
+	SourceSubjectIndex string    `gorm:"not null" json:"-"` // Blind index under SourceTenantID
+	ConsentTokenID     uuid.UUID `gorm:"type:uuid;not null" json:"consent_token_id"`
+	CreatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ReputationFederationLink) TableName() string {
+	return "reputation_federation_links"
+}
+
+// ReputationFederationTrust is how much weight a tenant gives scores read from a source tenant
+type ReputationFederationTrust struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID       uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	SourceTenantID uuid.UUID `gorm:"type:uuid;not null" json:"source_tenant_id"`
+	Weight         float64   `gorm:"type:decimal(4,3);not null" json:"weight"` // 0 ignores the source
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ReputationFederationTrust) TableName() string {
+	return "reputation_federation_trust"
+}
+
+// AuditCheckpoint is a signed Merkle root over a range of a tenant's audit events
+type AuditCheckpoint struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenantID     uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	FromSequence int64      `gorm:"not null" json:"from_sequence"`
+	ToSequence   int64      `gorm:"not null" json:"to_sequence"`
+	TreeSize     int64      `gorm:"not null" json:"tree_size"`
+	MerkleRoot   string     `gorm:"not null" json:"merkle_root"`
+	ChainHash    string     `gorm:"not null" json:"chain_hash"` // Hash of the event at ToSequence
+	PreviousID   *uuid.UUID `gorm:"type:uuid" json:"previous_id,omitempty"`
+	Statement    string     `gorm:"not null" json:"statement"` // Signed JSON, verbatim
+	Signature    string     `gorm:"type:jsonb;not null" json:"signature"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (AuditCheckpoint) TableName() string {
+	return "audit_checkpoints"
+}
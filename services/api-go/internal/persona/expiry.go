@@ -0,0 +1,62 @@
+package persona
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+)
+
+// Worker marks verifications past their expiry as expired
+func (s *Service) Worker(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireVerifications(ctx)
+		}
+	}
+}
+
+func (s *Service) expireVerifications(ctx context.Context) {
+	var expired []models.PersonaVerification
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", string(StatusVerified), time.Now()).
+		Limit(100).
+		Find(&expired).Error; err != nil {
+		log.Printf("Error fetching expired verifications: %v", err)
+		return
+	}
+
+	for i := range expired {
+		v := &expired[i]
+		result := s.db.WithContext(ctx).Model(&models.PersonaVerification{}).
+			Where("id = ? AND status = ?", v.ID, string(StatusVerified)).
+			Update("status", string(StatusExpired))
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.openVerification(ctx, v); err != nil {
+			log.Printf("Verification %s expired but could not be opened: %v", v.ID, err)
+			continue
+		}
+
+		s.audit.LogEvent(ctx, audit.LogEventInput{
+			TenantID:     v.TenantID,
+			EventType:    "persona.expired",
+			SubjectID:    &v.SubjectID,
+			ResourceType: stringPtr("verification"),
+			ResourceID:   &v.ID,
+			Metadata: map[string]interface{}{
+				"provider":   v.Provider,
+				"expires_at": v.ExpiresAt,
+			},
+		})
+	}
+}
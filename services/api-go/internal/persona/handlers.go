@@ -1,9 +1,11 @@
 package persona
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/pseudonym"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -32,6 +34,13 @@ func (h *Handler) CreateVerification(c *gin.Context) {
 	tenantID, _ := middleware.GetTenantID(c)
 
 	verification, err := h.service.CreateVerification(c.Request.Context(), tenantID, input)
+	if errors.Is(err, pseudonym.ErrAlreadyPseudonym) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_subject",
+			"message": "subject_id must be the tenant's own identifier, not a pseudonym",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "verification_error",
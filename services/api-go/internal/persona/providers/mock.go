@@ -45,7 +45,7 @@ func (p *MockProvider) Verify(ctx context.Context, input persona.VerificationInp
 				"mock_session_id": "mock_abc123",
 				"simulated":       true,
 			},
-			ProofHash:  "mock_proof_hash_xyz",
+			ProofHash:  "mock_proof_hash_" + input.SubjectID, // Stable per simulated person
 			VerifiedAt: &now,
 			ExpiresAt:  &expiresAt,
 		}, nil
@@ -4,22 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
-	"github.com/dennislee928/mighty-eagle/api-go/internal/webhooks"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/pseudonym"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // Service provides persona verification functionality
 type Service struct {
-	db        *gorm.DB
-	providers map[string]VerificationProvider
-	audit     *audit.Logger
-	webhooks  *webhooks.Service
-	billing   BillingService
+	db         *gorm.DB
+	providers  map[string]VerificationProvider
+	audit      *audit.Logger
+	billing    BillingService
+	crypto     *encryption.Service
+	pseudonyms *pseudonym.Service
 }
 
 // BillingService interface to avoid circular dependency
@@ -28,13 +29,14 @@ type BillingService interface {
 }
 
 // NewService creates a new persona service
-func NewService(db *gorm.DB, audit *audit.Logger, webhooks *webhooks.Service, billing BillingService) *Service {
+func NewService(db *gorm.DB, audit *audit.Logger, billing BillingService, crypto *encryption.Service, pseudonyms *pseudonym.Service) *Service {
 	return &Service{
-		db:        db,
-		providers: make(map[string]VerificationProvider),
-		audit:     audit,
-		webhooks:  webhooks,
-		billing:   billing,
+		db:         db,
+		providers:  make(map[string]VerificationProvider),
+		audit:      audit,
+		billing:    billing,
+		crypto:     crypto,
+		pseudonyms: pseudonyms,
 	}
 }
 
@@ -43,8 +45,9 @@ func (s *Service) RegisterProvider(provider VerificationProvider) {
 	s.providers[provider.Name()] = provider
 }
 
-// CreateVerification initiates a verification request
-func (s *Service) CreateVerification(ctx context.Context, tenantID uuid.UUID, input VerificationInput) (*models.PersonaVerification, error) {
+// VerifyProof checks a proof with its provider without recording a verification.
+// The result's ProofHash is the tenant's pseudonym for the provider identifier.
+func (s *Service) VerifyProof(ctx context.Context, tenantID uuid.UUID, input VerificationInput) (*VerificationResult, error) {
 	// Find provider
 	provider, ok := s.providers[input.Provider]
 	if !ok {
@@ -57,16 +60,45 @@ func (s *Service) CreateVerification(ctx context.Context, tenantID uuid.UUID, in
 		return nil, fmt.Errorf("verification failed: %w", err)
 	}
 
+	// Raw provider identifiers (e.g. nullifiers) are replaced by pairwise
+	// pseudonyms so they cannot be correlated across tenants
+	if result.ProofHash != "" {
+		if result.ProofHash, err = s.pseudonyms.Pseudonymize(ctx, tenantID, input.Provider, result.ProofHash); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// CreateVerification initiates a verification request
+func (s *Service) CreateVerification(ctx context.Context, tenantID uuid.UUID, input VerificationInput) (*models.PersonaVerification, error) {
+	// Reject before the provider is called; Pseudonymize would anyway
+	if pseudonym.IsPseudonym(input.SubjectID) {
+		return nil, pseudonym.ErrAlreadyPseudonym
+	}
+
+	result, err := s.VerifyProof(ctx, tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+
 	// Marshal provider data
 	providerDataJSON, err := json.Marshal(result.ProviderData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal provider data: %w", err)
 	}
 
+	subjectPseudonym, err := s.pseudonyms.Pseudonymize(ctx, tenantID, pseudonym.NamespaceSubject, input.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create record
 	verification := models.PersonaVerification{
 		TenantID:         tenantID,
 		SubjectID:        input.SubjectID,
+		SubjectPseudonym: subjectPseudonym,
 		Provider:         input.Provider,
 		Status:           string(result.Status),
 		ConfidenceScore:  &result.ConfidenceScore,
@@ -76,9 +108,16 @@ func (s *Service) CreateVerification(ctx context.Context, tenantID uuid.UUID, in
 		VerifiedAt:       result.VerifiedAt,
 	}
 
-	if err := s.db.Create(&verification).Error; err != nil {
+	sealed, err := s.sealVerification(ctx, verification)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&sealed).Error; err != nil {
 		return nil, fmt.Errorf("failed to create verification record: %w", err)
 	}
+	verification.ID = sealed.ID
+	verification.CreatedAt = sealed.CreatedAt
+	verification.UpdatedAt = sealed.UpdatedAt
 
 	// Report Billing Usage (Non-blocking)
 	go func() {
@@ -108,30 +147,6 @@ func (s *Service) CreateVerification(ctx context.Context, tenantID uuid.UUID, in
 		},
 	})
 
-	// Dispatch Webhook
-	go func() {
-		// Reconstruct event log metadata for webhook payload
-		meta, _ := json.Marshal(map[string]interface{}{
-			"provider": input.Provider,
-			"score":    result.ConfidenceScore,
-			"error":    result.Error,
-		})
-
-		dispatchEvt := models.EventLog{
-			ID:           uuid.New(), // Temporary ID for dispatch if not persisted yet
-			TenantID:     tenantID,
-			EventType:    eventType,
-			CreatedAt:    time.Now(),
-			SubjectID:    &input.SubjectID,
-			ResourceID:   &verification.ID,
-			Metadata:     string(meta),
-		}
-		
-		if err := s.webhooks.DispatchEvent(context.Background(), dispatchEvt); err != nil {
-			fmt.Printf("Failed to dispatch webhook: %v\n", err)
-		}
-	}()
-
 	return &verification, nil
 }
 
@@ -141,9 +156,45 @@ func (s *Service) GetVerification(ctx context.Context, tenantID uuid.UUID, id uu
 	if err := s.db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&verification).Error; err != nil {
 		return nil, err
 	}
+	if err := s.openVerification(ctx, &verification); err != nil {
+		return nil, err
+	}
 	return &verification, nil
 }
 
+// sealVerification returns a copy of the verification with sensitive fields encrypted
+func (s *Service) sealVerification(ctx context.Context, v models.PersonaVerification) (models.PersonaVerification, error) {
+	subject, err := s.crypto.Encrypt(ctx, v.TenantID, v.SubjectID)
+	if err != nil {
+		return v, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	data, err := s.crypto.EncryptJSON(ctx, v.TenantID, v.VerificationData)
+	if err != nil {
+		return v, fmt.Errorf("failed to encrypt verification data: %w", err)
+	}
+
+	v.SubjectIndex = s.crypto.BlindIndex(v.TenantID, v.SubjectID)
+	v.SubjectID = subject
+	v.VerificationData = data
+	return v, nil
+}
+
+// openVerification decrypts the sensitive fields of a verification in place
+func (s *Service) openVerification(ctx context.Context, v *models.PersonaVerification) error {
+	subject, err := s.crypto.Decrypt(ctx, v.TenantID, v.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt subject: %w", err)
+	}
+	data, err := s.crypto.DecryptJSON(ctx, v.TenantID, v.VerificationData)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt verification data: %w", err)
+	}
+
+	v.SubjectID = subject
+	v.VerificationData = data
+	return nil
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
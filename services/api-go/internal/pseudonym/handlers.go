@@ -0,0 +1,80 @@
+package pseudonym
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages pseudonym HTTP endpoints
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new pseudonym handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreatePseudonymInput is the request body for POST /v1/pseudonyms
+type CreatePseudonymInput struct {
+	ExternalID string `json:"external_id" binding:"required"`
+	Namespace  string `json:"namespace"`
+}
+
+// CreatePseudonym handles POST /v1/pseudonyms
+func (h *Handler) CreatePseudonym(c *gin.Context) {
+	var input CreatePseudonymInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+	if input.Namespace == "" {
+		input.Namespace = NamespaceSubject
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	pseudonym, err := h.service.Pseudonymize(c.Request.Context(), tenantID, input.Namespace, input.ExternalID)
+	if errors.Is(err, ErrAlreadyPseudonym) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_identifier",
+			"message": "external_id is already a pseudonym; resolve it instead",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "pseudonymization_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pseudonym": pseudonym,
+		"namespace": input.Namespace,
+	})
+}
+
+// ResolvePseudonym handles GET /v1/pseudonyms/:pseudonym
+// Only the tenant a pseudonym was issued to can resolve it.
+func (h *Handler) ResolvePseudonym(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	mapping, err := h.service.Resolve(c.Request.Context(), tenantID, c.Param("pseudonym"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Pseudonym not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
@@ -0,0 +1,129 @@
+package pseudonym
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NamespaceSubject is used for tenant supplied user identifiers.
+// Provider proof identifiers (e.g. World ID nullifiers) use the provider name,
+// keeping pseudonyms for different kinds of identifier apart.
+const NamespaceSubject = "subject"
+
+// prefix marks a value as a pseudonym
+const prefix = "psn_"
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrAlreadyPseudonym is returned when a pseudonym is passed where a raw
+// identifier is expected, which would pseudonymize it a second time
+var ErrAlreadyPseudonym = errors.New("identifier is already a pseudonym")
+
+// Service derives stable pairwise pseudonyms for external identifiers.
+// The same identifier yields a different pseudonym for every tenant, so
+// pseudonyms cannot be correlated across tenants. The external identifier
+// is kept encrypted so the owning tenant can resolve it.
+type Service struct {
+	db     *gorm.DB
+	crypto *encryption.Service
+	key    []byte
+}
+
+// NewService creates a pseudonym service keyed by PSEUDONYM_KEY
+func NewService(db *gorm.DB, crypto *encryption.Service) (*Service, error) {
+	key := []byte(os.Getenv("PSEUDONYM_KEY"))
+	if len(key) == 0 {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("PSEUDONYM_KEY is required in release mode")
+		}
+		// Development fallback so the API can start without extra setup.
+		log.Println("⚠️  PSEUDONYM_KEY not set, using insecure development pseudonym key")
+		key = []byte("mighty-eagle-dev-pseudonym-key")
+	}
+
+	return &Service{
+		db:     db,
+		crypto: crypto,
+		key:    key,
+	}, nil
+}
+
+// IsPseudonym reports whether a value looks like a pseudonym
+func IsPseudonym(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Derive computes the tenant's pseudonym for an identifier without storing it
+func (s *Service) Derive(tenantID uuid.UUID, namespace, externalID string) string {
+	tenantMAC := hmac.New(sha256.New, s.key)
+	tenantMAC.Write(tenantID[:])
+	tenantKey := tenantMAC.Sum(nil)
+
+	h := hmac.New(sha256.New, tenantKey)
+	h.Write([]byte(namespace + ":" + externalID))
+	return prefix + strings.ToLower(encoding.EncodeToString(h.Sum(nil)[:20]))
+}
+
+// Pseudonymize returns the tenant's pseudonym for an identifier and records
+// the encrypted mapping so it can be resolved later
+func (s *Service) Pseudonymize(ctx context.Context, tenantID uuid.UUID, namespace, externalID string) (string, error) {
+	if externalID == "" {
+		return "", fmt.Errorf("external identifier is required")
+	}
+	if IsPseudonym(externalID) {
+		return "", ErrAlreadyPseudonym
+	}
+	pseudonym := s.Derive(tenantID, namespace, externalID)
+
+	encrypted, err := s.crypto.Encrypt(ctx, tenantID, externalID)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt identifier: %w", err)
+	}
+
+	mapping := models.SubjectPseudonym{
+		TenantID:      tenantID,
+		Pseudonym:     pseudonym,
+		Namespace:     namespace,
+		ExternalID:    encrypted,
+		ExternalIndex: s.crypto.BlindIndex(tenantID, externalID),
+		CreatedAt:     time.Now(),
+	}
+	if err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "pseudonym"}}, DoNothing: true}).
+		Create(&mapping).Error; err != nil {
+		return "", fmt.Errorf("failed to store pseudonym: %w", err)
+	}
+
+	return pseudonym, nil
+}
+
+// Resolve returns the mapping for a pseudonym owned by the tenant
+func (s *Service) Resolve(ctx context.Context, tenantID uuid.UUID, pseudonym string) (*models.SubjectPseudonym, error) {
+	var mapping models.SubjectPseudonym
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND pseudonym = ?", tenantID, pseudonym).
+		First(&mapping).Error; err != nil {
+		return nil, err
+	}
+
+	externalID, err := s.crypto.Decrypt(ctx, tenantID, mapping.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt identifier: %w", err)
+	}
+	mapping.ExternalID = externalID
+	return &mapping, nil
+}
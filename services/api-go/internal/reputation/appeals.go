@@ -0,0 +1,254 @@
+package reputation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Appeal decisions a moderator can reach
+const (
+	AppealUpheld     = "upheld"     // The signal stands
+	AppealOverturned = "overturned" // The signal drops out of scoring
+)
+
+// ErrAppealConflict is returned when an appeal cannot move to the requested state
+var ErrAppealConflict = errors.New("appeal conflict")
+
+// FileAppealInput represents a subject's appeal of a signal
+type FileAppealInput struct {
+	SubjectID string `json:"subject_id" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// ResolveAppealInput represents a moderator's decision
+type ResolveAppealInput struct {
+	Decision    string  `json:"decision" binding:"required"` // upheld, overturned
+	ModeratorID string  `json:"moderator_id" binding:"required"`
+	Note        *string `json:"note"`
+}
+
+// ListAppealsInput filters the appeal queue
+type ListAppealsInput struct {
+	Status    string
+	SubjectID string
+	Limit     int
+	Offset    int
+}
+
+// FileAppeal opens an appeal against a negative signal about the subject.
+// A signal can be appealed once; the decision is final.
+func (s *Service) FileAppeal(ctx context.Context, tenantID uuid.UUID, signalID uuid.UUID, input FileAppealInput) (*models.SignalAppeal, error) {
+	subjectIndex := s.crypto.BlindIndex(tenantID, input.SubjectID)
+
+	var signal models.ReputationSignal
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ? AND subject_index = ?", signalID, tenantID, subjectIndex).
+		First(&signal).Error; err != nil {
+		return nil, err
+	}
+	// Negative as the active policy weighs it, which may override the stored weight
+	scorer, _, err := s.activePolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if scorer.signalWeight(&signal) >= 0 {
+		return nil, fmt.Errorf("%w: only negative signals can be appealed", ErrAppealConflict)
+	}
+	if signal.Status == "overturned" {
+		return nil, fmt.Errorf("%w: signal is already overturned", ErrAppealConflict)
+	}
+	var existing int64
+	if err := s.db.WithContext(ctx).Model(&models.SignalAppeal{}).Where("signal_id = ?", signal.ID).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing appeals: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("%w: signal has already been appealed", ErrAppealConflict)
+	}
+
+	encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, input.SubjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	encryptedReason, err := s.crypto.Encrypt(ctx, tenantID, input.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt reason: %w", err)
+	}
+
+	appeal := models.SignalAppeal{
+		TenantID:     tenantID,
+		SignalID:     signal.ID,
+		SubjectID:    encryptedSubject,
+		SubjectIndex: subjectIndex,
+		Status:       "open",
+		Reason:       encryptedReason,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&appeal).Error; err != nil {
+		return nil, fmt.Errorf("failed to file appeal: %w", err)
+	}
+
+	s.logAppealEvent(ctx, &appeal, input.SubjectID, "reputation.appeal_filed", map[string]interface{}{
+		"signal_id":   signal.ID,
+		"signal_type": signal.SignalType,
+	})
+
+	appeal.SubjectID = input.SubjectID
+	appeal.Reason = input.Reason
+	return &appeal, nil
+}
+
+// ResolveAppeal records a moderator's decision on an open appeal. Overturning
+// drops the signal out of scoring; the recorded event invalidates the cached score.
+func (s *Service) ResolveAppeal(ctx context.Context, tenantID uuid.UUID, appealID uuid.UUID, input ResolveAppealInput) (*models.SignalAppeal, error) {
+	if input.Decision != AppealUpheld && input.Decision != AppealOverturned {
+		return nil, fmt.Errorf("decision must be '%s' or '%s'", AppealUpheld, AppealOverturned)
+	}
+
+	var appeal models.SignalAppeal
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND tenant_id = ?", appealID, tenantID).First(&appeal).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.SignalAppeal{}).Where("id = ? AND status = ?", appeal.ID, "open").Updates(map[string]interface{}{
+			"status":          input.Decision,
+			"resolution_note": input.Note,
+			"resolved_by":     input.ModeratorID,
+			"resolved_at":     now,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to resolve appeal: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: appeal is already %s", ErrAppealConflict, appeal.Status)
+		}
+
+		if input.Decision == AppealOverturned {
+			if err := tx.Model(&models.ReputationSignal{}).Where("id = ?", appeal.SignalID).Updates(map[string]interface{}{
+				"status":        "overturned",
+				"overturned_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to overturn signal: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	appeal.Status = input.Decision
+	appeal.ResolutionNote = input.Note
+	appeal.ResolvedBy = &input.ModeratorID
+	appeal.ResolvedAt = &now
+
+	if err := s.openAppeal(ctx, &appeal); err != nil {
+		return nil, err
+	}
+	s.logAppealEvent(ctx, &appeal, appeal.SubjectID, "reputation.appeal_"+input.Decision, map[string]interface{}{
+		"signal_id":    appeal.SignalID,
+		"moderator_id": input.ModeratorID,
+	})
+
+	return &appeal, nil
+}
+
+// GetAppeal retrieves an appeal
+func (s *Service) GetAppeal(ctx context.Context, tenantID uuid.UUID, appealID uuid.UUID) (*models.SignalAppeal, error) {
+	var appeal models.SignalAppeal
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", appealID, tenantID).First(&appeal).Error; err != nil {
+		return nil, err
+	}
+	if err := s.openAppeal(ctx, &appeal); err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+// ListAppeals returns appeals for the moderation queue, oldest first
+func (s *Service) ListAppeals(ctx context.Context, tenantID uuid.UUID, input ListAppealsInput) ([]models.SignalAppeal, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.SignalAppeal{}).Where("tenant_id = ?", tenantID)
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+	if input.SubjectID != "" {
+		query = query.Where("subject_index = ?", s.crypto.BlindIndex(tenantID, input.SubjectID))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count appeals: %w", err)
+	}
+
+	if input.Limit <= 0 || input.Limit > 100 {
+		input.Limit = 100
+	}
+
+	var appeals []models.SignalAppeal
+	if err := query.Order("created_at ASC").Limit(input.Limit).Offset(input.Offset).Find(&appeals).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list appeals: %w", err)
+	}
+	for i := range appeals {
+		if err := s.openAppeal(ctx, &appeals[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return appeals, total, nil
+}
+
+// CountOpenAppeals returns the number of undecided appeals filed by a subject
+func (s *Service) CountOpenAppeals(ctx context.Context, tenantID uuid.UUID, subjectID string) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.SignalAppeal{}).
+		Where("tenant_id = ? AND subject_index = ? AND status = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID), "open").
+		Count(&count).Error
+	return count, err
+}
+
+// appealRefs maps signal IDs to the appeals filed against them
+func (s *Service) appealRefs(ctx context.Context, tenantID uuid.UUID, subjectID string) (map[string]*AppealRef, error) {
+	var appeals []models.SignalAppeal
+	if err := s.db.WithContext(ctx).Select("id", "signal_id", "status").
+		Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		Find(&appeals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load appeals: %w", err)
+	}
+	refs := make(map[string]*AppealRef, len(appeals))
+	for _, a := range appeals {
+		refs[a.SignalID.String()] = &AppealRef{ID: a.ID.String(), Status: a.Status}
+	}
+	return refs, nil
+}
+
+// openAppeal decrypts the sensitive fields of an appeal in place
+func (s *Service) openAppeal(ctx context.Context, appeal *models.SignalAppeal) error {
+	subject, err := s.crypto.Decrypt(ctx, appeal.TenantID, appeal.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt appeal subject: %w", err)
+	}
+	reason, err := s.crypto.Decrypt(ctx, appeal.TenantID, appeal.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt appeal reason: %w", err)
+	}
+	appeal.SubjectID = subject
+	appeal.Reason = reason
+	return nil
+}
+
+func (s *Service) logAppealEvent(ctx context.Context, appeal *models.SignalAppeal, subjectID string, eventType string, metadata map[string]interface{}) {
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     appeal.TenantID,
+		EventType:    eventType,
+		SubjectID:    &subjectID,
+		ResourceType: stringPtr("signal_appeal"),
+		ResourceID:   &appeal.ID,
+		Metadata:     metadata,
+	})
+}
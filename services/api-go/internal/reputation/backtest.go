@@ -0,0 +1,263 @@
+package reputation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// DefaultBacktestSubjects caps how many subjects are replayed per date
+const DefaultBacktestSubjects = 1000
+
+// BacktestInput describes a replay of a candidate strategy against production
+type BacktestInput struct {
+	Candidate   ScoringStrategy
+	Baseline    ScoringStrategy // Defaults to the policy version production used at each date
+	AsOf        []time.Time
+	MaxSubjects int
+}
+
+// Distribution summarises the scores of one strategy at one date
+type Distribution struct {
+	Mean   float64        `json:"mean"`
+	P10    float64        `json:"p10"`
+	Median float64        `json:"median"`
+	P90    float64        `json:"p90"`
+	Levels map[string]int `json:"levels"`
+}
+
+// BacktestPoint compares both strategies as of one date
+type BacktestPoint struct {
+	AsOf           time.Time      `json:"as_of"`
+	BaselinePolicy string         `json:"baseline_policy,omitempty"` // Production policy version at this date
+	Subjects       int            `json:"subjects"`
+	Baseline       Distribution   `json:"baseline"`
+	Candidate      Distribution   `json:"candidate"`
+	MeanAbsDelta   float64        `json:"mean_abs_delta"`
+	LevelChanges   int            `json:"level_changes"`
+	Transitions    map[string]int `json:"transitions"` // "Baseline level -> Candidate level" counts, changes only
+}
+
+// BacktestReport is the result of a backtest
+type BacktestReport struct {
+	Baseline  string          `json:"baseline"`
+	Candidate string          `json:"candidate"`
+	Points    []BacktestPoint `json:"points"`
+}
+
+// Backtest replays the event log and verification history as of each date
+// through the baseline and candidate strategies and compares the results.
+// Subjects are identified by blind index only, so nothing is decrypted.
+func (s *Service) Backtest(ctx context.Context, tenantID uuid.UUID, input BacktestInput) (*BacktestReport, error) {
+	if input.Candidate == nil {
+		return nil, fmt.Errorf("a candidate strategy is required")
+	}
+	maxSubjects := input.MaxSubjects
+	if maxSubjects <= 0 {
+		maxSubjects = DefaultBacktestSubjects
+	}
+
+	report := &BacktestReport{Candidate: input.Candidate.Name(), Baseline: "production"}
+	if input.Baseline != nil {
+		report.Baseline = input.Baseline.Name()
+	}
+
+	asOf := append([]time.Time(nil), input.AsOf...)
+	sort.Slice(asOf, func(i, j int) bool { return asOf[i].Before(asOf[j]) })
+
+	for _, at := range asOf {
+		var subjectIndexes []string
+		if err := s.db.WithContext(ctx).Model(&models.EventLog{}).
+			Where("tenant_id = ? AND subject_index IS NOT NULL AND created_at <= ?", tenantID, at).
+			Distinct("subject_index").Order("subject_index").Limit(maxSubjects).
+			Pluck("subject_index", &subjectIndexes).Error; err != nil {
+			return nil, fmt.Errorf("failed to list subjects: %w", err)
+		}
+
+		point := BacktestPoint{AsOf: at, Subjects: len(subjectIndexes), Transitions: make(map[string]int)}
+
+		// Production scored with whichever policy version was active then
+		baseline := input.Baseline
+		if baseline == nil {
+			scorer, policy, err := s.policyAsOf(ctx, tenantID, at)
+			if err != nil {
+				return nil, err
+			}
+			baseline = scorer
+			point.BaselinePolicy = fmt.Sprintf("%s v%d", policy.Name, policy.Version)
+		}
+
+		var baselineScores, candidateScores []float64
+		baselineLevels, candidateLevels := make(map[string]int), make(map[string]int)
+		totalDelta := 0.0
+
+		for start := 0; start < len(subjectIndexes); start += MaxBatchSize {
+			end := start + MaxBatchSize
+			if end > len(subjectIndexes) {
+				end = len(subjectIndexes)
+			}
+			inputs, err := s.loadInputsAsOf(ctx, tenantID, subjectIndexes[start:end], at)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, index := range subjectIndexes[start:end] {
+				baseScore, _ := baseline.Score(inputs[index], at)
+				candScore, _ := input.Candidate.Score(inputs[index], at)
+				baseLevel, candLevel := baseline.Level(baseScore), input.Candidate.Level(candScore)
+
+				baselineScores = append(baselineScores, baseScore)
+				candidateScores = append(candidateScores, candScore)
+				baselineLevels[baseLevel]++
+				candidateLevels[candLevel]++
+				totalDelta += math.Abs(candScore - baseScore)
+				if baseLevel != candLevel {
+					point.LevelChanges++
+					point.Transitions[baseLevel+" -> "+candLevel]++
+				}
+			}
+		}
+
+		point.Baseline = distribution(baselineScores, baselineLevels)
+		point.Candidate = distribution(candidateScores, candidateLevels)
+		if point.Subjects > 0 {
+			point.MeanAbsDelta = round2(totalDelta / float64(point.Subjects))
+		}
+		report.Points = append(report.Points, point)
+	}
+
+	return report, nil
+}
+
+// loadInputsAsOf rebuilds subjects' score inputs as they stood at a past time.
+// Records created later are dropped, appeals and anomalies resolved later are
+// undone and verification statuses are replayed from the event log.
+// Federated scores are not replayed.
+func (s *Service) loadInputsAsOf(ctx context.Context, tenantID uuid.UUID, subjectIndexes []string, at time.Time) (map[string]*ScoreInputs, error) {
+	inputs := make(map[string]*ScoreInputs, len(subjectIndexes))
+	for _, index := range subjectIndexes {
+		inputs[index] = &ScoreInputs{}
+	}
+
+	var verifications []models.PersonaVerification
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ? AND created_at <= ?", tenantID, subjectIndexes, at).
+		Order("created_at ASC").Find(&verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch verification history: %w", err)
+	}
+	if len(verifications) > 0 {
+		statuses, err := s.verificationStatusesAsOf(ctx, tenantID, verifications, at)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range verifications {
+			v.Status = statuses[v.ID]
+			inputs[v.SubjectIndex].Verifications = append(inputs[v.SubjectIndex].Verifications, v)
+		}
+	}
+
+	var signals []models.ReputationSignal
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ? AND occurred_at <= ? AND created_at <= ?", tenantID, subjectIndexes, at, at).
+		Order("occurred_at ASC").Find(&signals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reputation signals: %w", err)
+	}
+	for _, sig := range signals {
+		if sig.OverturnedAt != nil && sig.OverturnedAt.After(at) {
+			sig.Status = "active"
+			sig.OverturnedAt = nil
+		}
+		inputs[sig.SubjectIndex].Signals = append(inputs[sig.SubjectIndex].Signals, sig)
+	}
+
+	var badges []models.TrustBadge
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ? AND awarded_at <= ?", tenantID, subjectIndexes, at).
+		Order("awarded_at ASC").Find(&badges).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch trust badges: %w", err)
+	}
+	for _, b := range badges {
+		inputs[b.SubjectIndex].Badges = append(inputs[b.SubjectIndex].Badges, b)
+	}
+
+	if err := s.loadDampening(ctx, tenantID, inputs, at); err != nil {
+		return nil, err
+	}
+
+	return inputs, nil
+}
+
+// verificationStatusesAsOf returns each verification's status at a past time,
+// taken from its latest persona.* event. Verifications without events fall
+// back to their verified_at timestamp.
+func (s *Service) verificationStatusesAsOf(ctx context.Context, tenantID uuid.UUID, verifications []models.PersonaVerification, at time.Time) (map[uuid.UUID]string, error) {
+	ids := make([]uuid.UUID, len(verifications))
+	for i, v := range verifications {
+		ids[i] = v.ID
+	}
+
+	var rows []struct {
+		ResourceID uuid.UUID
+		EventType  string
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (resource_id) resource_id, event_type
+		FROM event_log
+		WHERE tenant_id = ? AND resource_type = 'verification' AND resource_id IN ?
+		  AND event_type LIKE 'persona.%' AND created_at <= ?
+		ORDER BY resource_id, created_at DESC`, tenantID, ids, at).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to replay verification events: %w", err)
+	}
+
+	statuses := make(map[uuid.UUID]string, len(verifications))
+	for _, v := range verifications {
+		switch {
+		case v.Status == "failed":
+			statuses[v.ID] = "failed"
+		case v.VerifiedAt != nil && !v.VerifiedAt.After(at):
+			statuses[v.ID] = "verified"
+		default:
+			statuses[v.ID] = "pending"
+		}
+	}
+	for _, row := range rows {
+		statuses[row.ResourceID] = strings.TrimPrefix(row.EventType, "persona.")
+	}
+	return statuses, nil
+}
+
+// distribution summarises a set of scores
+func distribution(scores []float64, levels map[string]int) Distribution {
+	d := Distribution{Levels: levels}
+	if len(scores) == 0 {
+		return d
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, score := range sorted {
+		sum += score
+	}
+	d.Mean = round2(sum / float64(len(sorted)))
+	d.P10 = percentile(sorted, 0.10)
+	d.Median = percentile(sorted, 0.50)
+	d.P90 = percentile(sorted, 0.90)
+	return d
+}
+
+// percentile uses the nearest-rank method on sorted scores
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,133 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxBatchSize caps the subjects in one batch lookup
+const MaxBatchSize = 200
+
+// BatchInput represents a batch reputation lookup
+type BatchInput struct {
+	SubjectIDs []string `json:"subject_ids" binding:"required"`
+}
+
+// BatchItem is the outcome for one subject of a batch; exactly one of
+// Reputation and Error is set
+type BatchItem struct {
+	SubjectID  string            `json:"subject_id"`
+	Reputation *ReputationResult `json:"reputation,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// GetReputationBatch resolves many subjects with one cache round trip and one
+// query per input table. Results follow the input order with duplicates
+// removed; a failing subject does not fail the batch.
+func (s *Service) GetReputationBatch(ctx context.Context, tenantID uuid.UUID, input BatchInput) ([]BatchItem, error) {
+	subjectIDs := make([]string, 0, len(input.SubjectIDs))
+	seen := make(map[string]bool, len(input.SubjectIDs))
+	for _, id := range input.SubjectIDs {
+		if !seen[id] {
+			seen[id] = true
+			subjectIDs = append(subjectIDs, id)
+		}
+	}
+	if len(subjectIDs) == 0 {
+		return nil, fmt.Errorf("at least one subject is required")
+	}
+	if len(subjectIDs) > MaxBatchSize {
+		return nil, fmt.Errorf("at most %d subjects per batch", MaxBatchSize)
+	}
+
+	scorer, policy, err := s.activePolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BatchItem, len(subjectIDs))
+	var misses []int
+	for i, id := range subjectIDs {
+		items[i].SubjectID = id
+		if id == "" {
+			items[i].Error = "subject_id is required"
+			continue
+		}
+		misses = append(misses, i)
+	}
+	if len(misses) == 0 {
+		return items, nil
+	}
+
+	// 1. Check Cache with a single MGET
+	keys := make([]string, len(misses))
+	for j, i := range misses {
+		keys[j] = s.cacheKey(tenantID, subjectIDs[i])
+	}
+	if values, err := s.redisClient.MGet(ctx, keys...).Result(); err == nil {
+		remaining := misses[:0]
+		for j, i := range misses {
+			if val, ok := values[j].(string); ok {
+				var result ReputationResult
+				if err := json.Unmarshal([]byte(val), &result); err == nil && samePolicy(result.Policy, policy) {
+					result.Cached = true
+					items[i].Reputation = &result
+					continue
+				}
+			}
+			remaining = append(remaining, i)
+		}
+		misses = remaining
+	}
+	if len(misses) == 0 {
+		return items, nil
+	}
+
+	// 2. Calculate the misses from one query per input table
+	indexes := make([]string, len(misses))
+	for j, i := range misses {
+		indexes[j] = s.crypto.BlindIndex(tenantID, subjectIDs[i])
+	}
+	inputs, err := s.loadInputsBatch(ctx, tenantID, indexes)
+	if err != nil {
+		for _, i := range misses {
+			items[i].Error = err.Error()
+		}
+		return items, nil
+	}
+
+	results := make([]*ReputationResult, len(misses))
+	for j, i := range misses {
+		results[j] = score(subjectIDs[i], inputs[indexes[j]], scorer, policy)
+	}
+
+	// 3. Cache Results (1 Hour TTL) in one pipeline
+	if _, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, result := range results {
+			jsonBytes, _ := json.Marshal(result)
+			pipe.Set(ctx, s.cacheKey(tenantID, result.SubjectID), jsonBytes, 1*time.Hour)
+		}
+		return nil
+	}); err != nil {
+		// Results are still valid; the next read recalculates
+		log.Printf("Failed to cache batch reputation: %v", err)
+	}
+
+	// 4. Persist Score Snapshots
+	errs := s.recordSnapshots(ctx, tenantID, results)
+	for j, i := range misses {
+		if errs[j] != nil {
+			items[i].Error = errs[j].Error()
+			continue
+		}
+		items[i].Reputation = results[j]
+	}
+
+	return items, nil
+}
@@ -0,0 +1,729 @@
+package reputation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Anomaly kinds raised by the trust graph analyzer
+const (
+	AnomalyReciprocalRing  = "reciprocal_ring"  // Dense cluster of subjects vouching for each other
+	AnomalySockPuppets     = "sock_puppets"     // Several freshly verified subjects vouching for one target
+	AnomalySharedNullifier = "shared_nullifier" // Both ends of an edge verified as the same person
+)
+
+// AnomalyDampening is the fraction of a flagged edge's positive contribution
+// that still counts while its anomaly is open
+var AnomalyDampening = map[string]float64{
+	AnomalyReciprocalRing:  0.25,
+	AnomalySockPuppets:     0.5,
+	AnomalySharedNullifier: 0,
+}
+
+// ErrAnomalyConflict is returned when an anomaly is no longer open
+var ErrAnomalyConflict = errors.New("anomaly conflict")
+
+const (
+	// graphInterval is how often the analyzer reads new edges
+	graphInterval = 5 * time.Minute
+
+	// graphLag keeps the analyzer behind the newest edges so rows from
+	// transactions still in flight are not skipped by the cursor
+	graphLag = time.Minute
+
+	// graphBatchSize bounds the new edges read per tenant and run
+	graphBatchSize = 1000
+
+	// maxNeighbourhood bounds the subjects loaded around new edges
+	maxNeighbourhood = 2000
+
+	minRingSize    = 3
+	maxRingSize    = 25 // Larger components are communities rather than rings
+	minRingDensity = 0.6
+
+	freshVerificationWindow = 72 * time.Hour
+	minSockPuppets          = 3
+)
+
+// ListAnomaliesInput filters the anomaly queue
+type ListAnomaliesInput struct {
+	Status    string
+	Kind      string
+	SubjectID string
+	Limit     int
+	Offset    int
+}
+
+// DismissAnomalyInput represents a moderator clearing an anomaly
+type DismissAnomalyInput struct {
+	ModeratorID string  `json:"moderator_id" binding:"required"`
+	Note        *string `json:"note"`
+}
+
+// graphEdge is a positive endorsement signal or a link badge between two subjects
+type graphEdge struct {
+	FromIndex string
+	ToIndex   string
+	FromID    *string // Encrypted; nil for the counterpart side of a badge
+	ToID      *string
+	Kind      string // endorsement, link
+	At        time.Time
+}
+
+// anomalyCandidate is a cluster found in one analyzer run
+type anomalyCandidate struct {
+	kind     string
+	members  map[string]string // Blind index to role
+	evidence map[string]interface{}
+}
+
+// GraphWorker analyzes new endorsement and link edges of every tenant
+func (s *Service) GraphWorker(ctx context.Context) {
+	ticker := time.NewTicker(graphInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var tenantIDs []uuid.UUID
+			if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("status = ?", "active").
+				Pluck("id", &tenantIDs).Error; err != nil {
+				log.Printf("Error listing tenants for trust graph analysis: %v", err)
+				continue
+			}
+			for _, tenantID := range tenantIDs {
+				if err := s.AnalyzeTrustGraph(ctx, tenantID); err != nil {
+					log.Printf("Trust graph analysis failed for tenant %s: %v", tenantID, err)
+				}
+			}
+		}
+	}
+}
+
+// AnalyzeTrustGraph reads the tenant's edges recorded since the last run,
+// looks for anomalies in the neighbourhood they touch and advances the cursor.
+func (s *Service) AnalyzeTrustGraph(ctx context.Context, tenantID uuid.UUID) error {
+	var cursor models.TrustGraphCursor
+	err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&cursor).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to load cursor: %w", err)
+	}
+	cursor.TenantID = tenantID
+
+	until := time.Now().Add(-graphLag)
+	fresh, err := s.loadEdges(ctx, tenantID, "created_at > @after AND created_at <= @until", "awarded_at > @after AND awarded_at <= @until", false,
+		map[string]interface{}{"after": cursor.ProcessedUntil, "until": until, "limit": graphBatchSize})
+	if err != nil {
+		return err
+	}
+	newVerified, err := s.newlyVerified(ctx, tenantID, cursor.ProcessedUntil, until)
+	if err != nil {
+		return err
+	}
+
+	// A full batch may stop mid-timestamp; leave that timestamp for the next run
+	if len(fresh) == graphBatchSize {
+		last := fresh[len(fresh)-1].At
+		trimmed := fresh[:0:0]
+		for _, e := range fresh {
+			if e.At.Before(last) {
+				trimmed = append(trimmed, e)
+			}
+		}
+		if len(trimmed) > 0 {
+			fresh, until = trimmed, trimmed[len(trimmed)-1].At
+		} else {
+			// The whole batch shares one timestamp: read every edge at it
+			// (LIMIT NULL is no limit) so the cursor can move past it
+			fresh, err = s.loadEdges(ctx, tenantID, "created_at = @at", "awarded_at = @at", false,
+				map[string]interface{}{"at": last, "limit": nil})
+			if err != nil {
+				return err
+			}
+			until = last
+		}
+	}
+
+	dirty := make(map[string]bool)
+	for _, e := range fresh {
+		dirty[e.FromIndex], dirty[e.ToIndex] = true, true
+	}
+	for _, index := range newVerified {
+		dirty[index] = true
+	}
+
+	if len(dirty) > 0 {
+		if err := s.analyzeNeighbourhood(ctx, tenantID, dirty); err != nil {
+			return err
+		}
+	}
+
+	cursor.ProcessedUntil = until
+	cursor.UpdatedAt = time.Now()
+	return s.db.WithContext(ctx).Save(&cursor).Error
+}
+
+// analyzeNeighbourhood runs the detectors over the subjects touched by new
+// edges and their direct neighbours. Edges are read newest first, so when a
+// busy subject's neighbourhood is capped the new edges that triggered the
+// run are the ones kept.
+func (s *Service) analyzeNeighbourhood(ctx context.Context, tenantID uuid.UUID, dirty map[string]bool) error {
+	touched, err := s.loadEdges(ctx, tenantID, "(source_index IN @set OR subject_index IN @set)", "(subject_index IN @set OR linked_subject_index IN @set)", true,
+		map[string]interface{}{"set": keys(dirty), "limit": maxNeighbourhood * 10})
+	if err != nil {
+		return err
+	}
+	neighbourhood := make(map[string]bool, len(dirty))
+	for index := range dirty {
+		neighbourhood[index] = true
+	}
+	for _, e := range touched {
+		if len(neighbourhood) >= maxNeighbourhood {
+			break
+		}
+		neighbourhood[e.FromIndex], neighbourhood[e.ToIndex] = true, true
+	}
+
+	set := keys(neighbourhood)
+	edges, err := s.loadEdges(ctx, tenantID, "source_index IN @set AND subject_index IN @set", "subject_index IN @set AND linked_subject_index IN @set", true,
+		map[string]interface{}{"set": set, "limit": maxNeighbourhood * 10})
+	if err != nil {
+		return err
+	}
+	verifiedAt, proofs, err := s.loadVerificationFacts(ctx, tenantID, set)
+	if err != nil {
+		return err
+	}
+
+	var candidates []anomalyCandidate
+	candidates = append(candidates, detectRings(edges, dirty)...)
+	candidates = append(candidates, detectSockPuppets(edges, dirty, verifiedAt)...)
+	candidates = append(candidates, detectSharedNullifiers(edges, dirty, proofs)...)
+
+	encryptedIDs := make(map[string]string)
+	for _, e := range edges {
+		if e.FromID != nil {
+			encryptedIDs[e.FromIndex] = *e.FromID
+		}
+		if e.ToID != nil {
+			encryptedIDs[e.ToIndex] = *e.ToID
+		}
+	}
+	for _, candidate := range candidates {
+		if err := s.raiseAnomaly(ctx, tenantID, candidate, encryptedIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEdges reads endorsement and link edges matching the given conditions,
+// oldest first or newest first
+func (s *Service) loadEdges(ctx context.Context, tenantID uuid.UUID, signalCond, badgeCond string, newestFirst bool, args map[string]interface{}) ([]graphEdge, error) {
+	args["tenant"] = tenantID
+	order := "ASC"
+	if newestFirst {
+		order = "DESC"
+	}
+	var edges []graphEdge
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT source_index AS from_index, subject_index AS to_index, source_id AS from_id, subject_id AS to_id,
+			       'endorsement' AS kind, created_at AS at
+			FROM reputation_signals
+			WHERE tenant_id = @tenant AND source_index IS NOT NULL AND weight > 0 AND status = 'active' AND `+signalCond+`
+			UNION ALL
+			SELECT subject_index, linked_subject_index, subject_id, NULL, 'link', awarded_at
+			FROM trust_badges
+			WHERE tenant_id = @tenant AND linked_subject_index IS NOT NULL AND `+badgeCond+`
+		) edges
+		ORDER BY at `+order+`
+		LIMIT @limit`, args).Scan(&edges).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trust graph edges: %w", err)
+	}
+	return edges, nil
+}
+
+// newlyVerified returns subjects verified within the window, whose earlier
+// edges may now look like sock puppets or share a nullifier
+func (s *Service) newlyVerified(ctx context.Context, tenantID uuid.UUID, after, until time.Time) ([]string, error) {
+	var indexes []string
+	if err := s.db.WithContext(ctx).Model(&models.PersonaVerification{}).
+		Where("tenant_id = ? AND status = ? AND created_at > ? AND created_at <= ?", tenantID, "verified", after, until).
+		Distinct("subject_index").Limit(graphBatchSize).Pluck("subject_index", &indexes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load new verifications: %w", err)
+	}
+	return indexes, nil
+}
+
+// loadVerificationFacts returns when each subject was first verified and the
+// proof pseudonyms (nullifiers) each subject verified with
+func (s *Service) loadVerificationFacts(ctx context.Context, tenantID uuid.UUID, subjectIndexes []string) (map[string]time.Time, map[string][]string, error) {
+	var verifications []models.PersonaVerification
+	if err := s.db.WithContext(ctx).Select("subject_index", "status", "proof_hash", "verified_at", "created_at").
+		Where("tenant_id = ? AND subject_index IN ? AND status IN ?", tenantID, subjectIndexes, []string{"verified", "expired"}).
+		Find(&verifications).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load verifications: %w", err)
+	}
+
+	verifiedAt := make(map[string]time.Time)
+	proofs := make(map[string][]string)
+	for _, v := range verifications {
+		at := v.CreatedAt
+		if v.VerifiedAt != nil {
+			at = *v.VerifiedAt
+		}
+		if first, ok := verifiedAt[v.SubjectIndex]; !ok || at.Before(first) {
+			verifiedAt[v.SubjectIndex] = at
+		}
+		if v.ProofHash != nil && *v.ProofHash != "" {
+			proofs[v.SubjectIndex] = append(proofs[v.SubjectIndex], *v.ProofHash)
+		}
+	}
+	return verifiedAt, proofs, nil
+}
+
+// detectRings finds small, dense components of reciprocal edges. A pair is
+// reciprocal when both endorsed each other or they completed a link; at least
+// half of a ring's pairs must be mutual endorsements, so groups of friends
+// that only link in person are not flagged.
+func detectRings(edges []graphEdge, dirty map[string]bool) []anomalyCandidate {
+	endorsed := make(map[[2]string]bool)
+	linked := make(map[[2]string]bool)
+	for _, e := range edges {
+		if e.FromIndex == e.ToIndex {
+			continue
+		}
+		if e.Kind == "link" {
+			linked[pairKey(e.FromIndex, e.ToIndex)] = true
+		} else {
+			endorsed[[2]string{e.FromIndex, e.ToIndex}] = true
+		}
+	}
+
+	adjacency := make(map[string]map[string]bool)
+	mutual := make(map[[2]string]bool)
+	addPair := func(pair [2]string) {
+		for i := 0; i < 2; i++ {
+			if adjacency[pair[i]] == nil {
+				adjacency[pair[i]] = make(map[string]bool)
+			}
+			adjacency[pair[i]][pair[1-i]] = true
+		}
+	}
+	for edge := range endorsed {
+		if endorsed[[2]string{edge[1], edge[0]}] {
+			pair := pairKey(edge[0], edge[1])
+			mutual[pair] = true
+			addPair(pair)
+		}
+	}
+	for pair := range linked {
+		addPair(pair)
+	}
+
+	var candidates []anomalyCandidate
+	seen := make(map[string]bool)
+	for _, start := range sortedKeys(adjacency) {
+		if seen[start] {
+			continue
+		}
+		component := []string{start}
+		seen[start] = true
+		for i := 0; i < len(component); i++ {
+			for next := range adjacency[component[i]] {
+				if !seen[next] {
+					seen[next] = true
+					component = append(component, next)
+				}
+			}
+		}
+		if len(component) < minRingSize || len(component) > maxRingSize || !touchesAny(component, dirty) {
+			continue
+		}
+
+		pairs, mutualPairs := 0, 0
+		for _, a := range component {
+			for b := range adjacency[a] {
+				if a < b {
+					pairs++
+					if mutual[pairKey(a, b)] {
+						mutualPairs++
+					}
+				}
+			}
+		}
+		n := len(component)
+		density := float64(pairs) / float64(n*(n-1)/2)
+		if density < minRingDensity || mutualPairs*2 < pairs {
+			continue
+		}
+
+		members := make(map[string]string, n)
+		for _, index := range component {
+			members[index] = "member"
+		}
+		candidates = append(candidates, anomalyCandidate{
+			kind:    AnomalyReciprocalRing,
+			members: members,
+			evidence: map[string]interface{}{
+				"reciprocal_pairs":    pairs,
+				"mutual_endorsements": mutualPairs,
+				"density":             round2(density),
+			},
+		})
+	}
+	return candidates
+}
+
+// detectSockPuppets finds targets vouched for by several subjects within
+// days of those subjects' first verification
+func detectSockPuppets(edges []graphEdge, dirty map[string]bool, verifiedAt map[string]time.Time) []anomalyCandidate {
+	fresh := make(map[string]map[string]bool) // Target to fresh sources
+	for _, e := range edges {
+		first, ok := verifiedAt[e.FromIndex]
+		if !ok || e.FromIndex == e.ToIndex {
+			continue
+		}
+		if age := e.At.Sub(first); age < 0 || age > freshVerificationWindow {
+			continue
+		}
+		if fresh[e.ToIndex] == nil {
+			fresh[e.ToIndex] = make(map[string]bool)
+		}
+		fresh[e.ToIndex][e.FromIndex] = true
+	}
+
+	var candidates []anomalyCandidate
+	for _, target := range sortedKeys(fresh) {
+		sources := fresh[target]
+		if len(sources) < minSockPuppets {
+			continue
+		}
+		if !dirty[target] && !touchesAny(keys(sources), dirty) {
+			continue
+		}
+		members := map[string]string{target: "target"}
+		for source := range sources {
+			members[source] = "source"
+		}
+		candidates = append(candidates, anomalyCandidate{
+			kind:    AnomalySockPuppets,
+			members: members,
+			evidence: map[string]interface{}{
+				"fresh_sources":      len(sources),
+				"fresh_window_hours": freshVerificationWindow.Hours(),
+			},
+		})
+	}
+	return candidates
+}
+
+// detectSharedNullifiers finds edges whose ends verified with the same proof,
+// i.e. one person vouching for themselves under a second subject ID
+func detectSharedNullifiers(edges []graphEdge, dirty map[string]bool, proofs map[string][]string) []anomalyCandidate {
+	var candidates []anomalyCandidate
+	seen := make(map[[2]string]bool)
+	for _, e := range edges {
+		pair := pairKey(e.FromIndex, e.ToIndex)
+		if e.FromIndex == e.ToIndex || seen[pair] || !(dirty[e.FromIndex] || dirty[e.ToIndex]) {
+			continue
+		}
+		seen[pair] = true
+
+		shared := 0
+		for _, a := range proofs[e.FromIndex] {
+			for _, b := range proofs[e.ToIndex] {
+				if a == b {
+					shared++
+				}
+			}
+		}
+		if shared == 0 {
+			continue
+		}
+		candidates = append(candidates, anomalyCandidate{
+			kind:     AnomalySharedNullifier,
+			members:  map[string]string{pair[0]: "member", pair[1]: "member"},
+			evidence: map[string]interface{}{"shared_proofs": shared, "edge_kind": e.Kind},
+		})
+	}
+	return candidates
+}
+
+// raiseAnomaly stores a candidate unless the same cluster was raised before,
+// then notifies moderators and drops the members' cached scores
+func (s *Service) raiseAnomaly(ctx context.Context, tenantID uuid.UUID, candidate anomalyCandidate, encryptedIDs map[string]string) error {
+	indexes := make([]string, 0, len(candidate.members))
+	for index := range candidate.members {
+		// Members without a stored identifier were erased
+		if _, ok := encryptedIDs[index]; ok {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) < 2 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	digest := sha256.Sum256([]byte(candidate.kind + ":" + strings.Join(indexes, ",")))
+	evidence, _ := json.Marshal(candidate.evidence)
+	anomaly := models.TrustAnomaly{
+		TenantID:    tenantID,
+		Kind:        candidate.kind,
+		Fingerprint: hex.EncodeToString(digest[:]),
+		Status:      "open",
+		Dampening:   AnomalyDampening[candidate.kind],
+		Evidence:    string(evidence),
+		MemberCount: len(indexes),
+		DetectedAt:  time.Now(),
+	}
+
+	raised := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Members").Create(&anomaly)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create anomaly: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		raised = true
+
+		members := make([]models.TrustAnomalyMember, len(indexes))
+		for i, index := range indexes {
+			members[i] = models.TrustAnomalyMember{
+				AnomalyID:    anomaly.ID,
+				TenantID:     tenantID,
+				SubjectID:    encryptedIDs[index],
+				SubjectIndex: index,
+				Role:         candidate.members[index],
+			}
+		}
+		anomaly.Members = members
+		return tx.Create(&members).Error
+	})
+	if err != nil || !raised {
+		return err
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "reputation.anomaly",
+		ResourceType: stringPtr("trust_anomaly"),
+		ResourceID:   &anomaly.ID,
+		Metadata: map[string]interface{}{
+			"kind":         anomaly.Kind,
+			"member_count": anomaly.MemberCount,
+			"dampening":    anomaly.Dampening,
+			"evidence":     candidate.evidence,
+		},
+	})
+	s.invalidateMembers(ctx, tenantID, anomaly.Members)
+	return nil
+}
+
+// ListAnomalies returns the tenant's anomaly queue, newest first
+func (s *Service) ListAnomalies(ctx context.Context, tenantID uuid.UUID, input ListAnomaliesInput) ([]models.TrustAnomaly, int64, error) {
+	if input.Limit <= 0 || input.Limit > 100 {
+		input.Limit = 100
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.TrustAnomaly{}).Where("tenant_id = ?", tenantID)
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+	if input.Kind != "" {
+		query = query.Where("kind = ?", input.Kind)
+	}
+	if input.SubjectID != "" {
+		query = query.Where("id IN (?)", s.db.Model(&models.TrustAnomalyMember{}).Select("anomaly_id").
+			Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, input.SubjectID)))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count anomalies: %w", err)
+	}
+	var anomalies []models.TrustAnomaly
+	if err := query.Preload("Members").Order("detected_at DESC").Limit(input.Limit).Offset(input.Offset).
+		Find(&anomalies).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list anomalies: %w", err)
+	}
+	for i := range anomalies {
+		if err := s.openMembers(ctx, tenantID, anomalies[i].Members); err != nil {
+			return nil, 0, err
+		}
+	}
+	return anomalies, total, nil
+}
+
+// GetAnomaly retrieves an anomaly with its members
+func (s *Service) GetAnomaly(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.TrustAnomaly, error) {
+	var anomaly models.TrustAnomaly
+	if err := s.db.WithContext(ctx).Preload("Members").Where("id = ? AND tenant_id = ?", id, tenantID).First(&anomaly).Error; err != nil {
+		return nil, err
+	}
+	if err := s.openMembers(ctx, tenantID, anomaly.Members); err != nil {
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+// DismissAnomaly clears an open anomaly; its edges count in full again
+func (s *Service) DismissAnomaly(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, input DismissAnomalyInput) (*models.TrustAnomaly, error) {
+	var anomaly models.TrustAnomaly
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ? AND tenant_id = ?", id, tenantID).
+			First(&anomaly).Error; err != nil {
+			return err
+		}
+		if anomaly.Status != "open" {
+			return fmt.Errorf("%w: anomaly is already %s", ErrAnomalyConflict, anomaly.Status)
+		}
+
+		now := time.Now()
+		anomaly.Status = "dismissed"
+		anomaly.ResolvedBy = &input.ModeratorID
+		anomaly.ResolvedAt = &now
+		anomaly.Note = input.Note
+		return tx.Model(&models.TrustAnomaly{}).Where("id = ?", anomaly.ID).Updates(map[string]interface{}{
+			"status":      anomaly.Status,
+			"resolved_by": anomaly.ResolvedBy,
+			"resolved_at": anomaly.ResolvedAt,
+			"note":        anomaly.Note,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Where("anomaly_id = ?", anomaly.ID).Find(&anomaly.Members).Error; err != nil {
+		return nil, fmt.Errorf("failed to load anomaly members: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "reputation.anomaly_dismissed",
+		ActorID:      &input.ModeratorID,
+		ResourceType: stringPtr("trust_anomaly"),
+		ResourceID:   &anomaly.ID,
+		Metadata: map[string]interface{}{
+			"kind": anomaly.Kind,
+		},
+	})
+	s.invalidateMembers(ctx, tenantID, anomaly.Members)
+
+	if err := s.openMembers(ctx, tenantID, anomaly.Members); err != nil {
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+// loadDampening fills in the dampening factors of subjects that were members
+// of an open anomaly at the given time. A counterpart in several anomalies
+// with a subject gets the strongest dampening.
+func (s *Service) loadDampening(ctx context.Context, tenantID uuid.UUID, inputs map[string]*ScoreInputs, at time.Time) error {
+	subjectIndexes := make([]string, 0, len(inputs))
+	for index := range inputs {
+		subjectIndexes = append(subjectIndexes, index)
+	}
+
+	var rows []struct {
+		SubjectIndex     string
+		CounterpartIndex string
+		Dampening        float64
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT m.subject_index, o.subject_index AS counterpart_index, MIN(a.dampening) AS dampening
+		FROM trust_anomaly_members m
+		JOIN trust_anomaly_members o ON o.anomaly_id = m.anomaly_id AND o.subject_index <> m.subject_index
+		JOIN trust_anomalies a ON a.id = m.anomaly_id
+		WHERE a.tenant_id = ? AND m.subject_index IN ?
+		  AND a.detected_at <= ? AND (a.resolved_at IS NULL OR a.resolved_at > ?)
+		GROUP BY m.subject_index, o.subject_index`, tenantID, subjectIndexes, at, at).Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load collusion dampening: %w", err)
+	}
+
+	for _, row := range rows {
+		in := inputs[row.SubjectIndex]
+		if in.Dampening == nil {
+			in.Dampening = make(map[string]float64)
+		}
+		in.Dampening[row.CounterpartIndex] = row.Dampening
+	}
+	return nil
+}
+
+// invalidateMembers drops the cached scores of an anomaly's members
+func (s *Service) invalidateMembers(ctx context.Context, tenantID uuid.UUID, members []models.TrustAnomalyMember) {
+	for _, m := range members {
+		subjectID, err := s.crypto.Decrypt(ctx, tenantID, m.SubjectID)
+		if err != nil {
+			log.Printf("Failed to decrypt anomaly member: %v", err)
+			continue
+		}
+		s.invalidate(ctx, tenantID, subjectID)
+	}
+}
+
+// openMembers decrypts member subject IDs in place
+func (s *Service) openMembers(ctx context.Context, tenantID uuid.UUID, members []models.TrustAnomalyMember) error {
+	for i := range members {
+		subjectID, err := s.crypto.Decrypt(ctx, tenantID, members[i].SubjectID)
+		if err != nil {
+			return err
+		}
+		members[i].SubjectID = subjectID
+	}
+	return nil
+}
+
+func pairKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+func touchesAny(indexes []string, dirty map[string]bool) bool {
+	for _, index := range indexes {
+		if dirty[index] {
+			return true
+		}
+	}
+	return false
+}
+
+func keys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	return out
+}
+
+func sortedKeys(m map[string]map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
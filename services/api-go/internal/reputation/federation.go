@@ -0,0 +1,417 @@
+package reputation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/consent"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrFederationConsent is returned when a consent token does not allow the link
+	ErrFederationConsent = errors.New("federation consent not granted")
+	// ErrFederationConflict is returned when the subject is already linked to the source tenant
+	ErrFederationConflict = errors.New("federation conflict")
+)
+
+// FederatedScore is a subject's score at another tenant, read under consent
+type FederatedScore struct {
+	LinkID         uuid.UUID
+	SourceTenantID uuid.UUID
+	Score          float64
+	Level          string
+	Weight         float64 // The reading tenant's trust in the source, 0..1
+}
+
+// LinkFederationInput names the consent under which a subject's reputation
+// at another tenant is read
+type LinkFederationInput struct {
+	SourceTenantID uuid.UUID `json:"source_tenant_id"`
+	ConsentTokenID uuid.UUID `json:"consent_token_id"` // Issued by the source tenant
+}
+
+// SetFederationTrustInput sets the weight given to a source tenant
+type SetFederationTrustInput struct {
+	Weight *float64 `json:"weight" binding:"required"`
+}
+
+// federationGrant is a federation consent token with its parties resolved
+type federationGrant struct {
+	Token     models.ConsentToken
+	SubjectID string      // Subject as known to the source tenant
+	Readers   []uuid.UUID // Tenants allowed to read
+}
+
+// allows reports whether the grant currently lets a tenant read the subject
+// behind a source blind index
+func (g *federationGrant) allows(s *Service, reader uuid.UUID, sourceSubjectIndex string, at time.Time) bool {
+	if g.Token.Status != "active" || !g.Token.ExpiresAt.After(at) {
+		return false
+	}
+	if s.crypto.BlindIndex(g.Token.TenantID, g.SubjectID) != sourceSubjectIndex {
+		return false // The subject was erased at the source
+	}
+	for _, tenantID := range g.Readers {
+		if tenantID == reader {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkFederation links a subject to their reputation at a source tenant. The
+// consent token must be an active reputation.federation consent issued by
+// the source tenant that names the reading tenant as a party.
+func (s *Service) LinkFederation(ctx context.Context, tenantID uuid.UUID, subjectID string, input LinkFederationInput) (*models.ReputationFederationLink, error) {
+	if input.SourceTenantID == uuid.Nil || input.ConsentTokenID == uuid.Nil {
+		return nil, fmt.Errorf("%w: source_tenant_id and consent_token_id are required", ErrFederationConsent)
+	}
+
+	grants, err := s.federationGrants(ctx, []uuid.UUID{input.ConsentTokenID})
+	if err != nil {
+		return nil, err
+	}
+	grant, ok := grants[input.ConsentTokenID]
+	if !ok || grant.Token.TenantID != input.SourceTenantID {
+		return nil, fmt.Errorf("%w: consent token not found at the source tenant", ErrFederationConsent)
+	}
+	sourceIndex := s.crypto.BlindIndex(input.SourceTenantID, grant.SubjectID)
+	if !grant.allows(s, tenantID, sourceIndex, time.Now()) {
+		return nil, fmt.Errorf("%w: consent is not active or does not name this tenant", ErrFederationConsent)
+	}
+
+	encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	link := models.ReputationFederationLink{
+		TenantID:           tenantID,
+		SubjectID:          encryptedSubject,
+		SubjectIndex:       s.crypto.BlindIndex(tenantID, subjectID),
+		SourceTenantID:     input.SourceTenantID,
+		SourceSubjectIndex: sourceIndex,
+		ConsentTokenID:     input.ConsentTokenID,
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&link)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create federation link: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: subject is already linked to tenant %s", ErrFederationConflict, input.SourceTenantID)
+	}
+
+	s.logFederation(ctx, "reputation.federation_linked", &link, subjectID, grant.SubjectID, nil)
+	s.invalidate(ctx, tenantID, subjectID)
+
+	link.SubjectID = subjectID
+	return &link, nil
+}
+
+// ListFederationLinks returns a subject's federation links
+func (s *Service) ListFederationLinks(ctx context.Context, tenantID uuid.UUID, subjectID string) ([]models.ReputationFederationLink, error) {
+	var links []models.ReputationFederationLink
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		Order("created_at ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to list federation links: %w", err)
+	}
+	for i := range links {
+		links[i].SubjectID = subjectID
+	}
+	return links, nil
+}
+
+// UnlinkFederation stops reading a subject's reputation at a source tenant
+func (s *Service) UnlinkFederation(ctx context.Context, tenantID uuid.UUID, subjectID string, linkID uuid.UUID) (bool, error) {
+	var link models.ReputationFederationLink
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ? AND subject_index = ?", linkID, tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load federation link: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Delete(&link).Error; err != nil {
+		return false, fmt.Errorf("failed to delete federation link: %w", err)
+	}
+
+	sourceSubject := ""
+	if grants, err := s.federationGrants(ctx, []uuid.UUID{link.ConsentTokenID}); err == nil {
+		if grant, ok := grants[link.ConsentTokenID]; ok {
+			sourceSubject = grant.SubjectID
+		}
+	}
+	s.logFederation(ctx, "reputation.federation_unlinked", &link, subjectID, sourceSubject, nil)
+	s.invalidate(ctx, tenantID, subjectID)
+	return true, nil
+}
+
+// SetFederationTrust sets how much weight scores read from a source tenant carry
+func (s *Service) SetFederationTrust(ctx context.Context, tenantID uuid.UUID, sourceTenantID uuid.UUID, input SetFederationTrustInput) (*models.ReputationFederationTrust, error) {
+	if *input.Weight < 0 || *input.Weight > 1 {
+		return nil, fmt.Errorf("weight must be between 0 and 1")
+	}
+	if sourceTenantID == tenantID {
+		return nil, fmt.Errorf("a tenant cannot federate with itself")
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("id = ?", sourceTenantID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up source tenant: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("tenant %s not found", sourceTenantID)
+	}
+
+	trust := models.ReputationFederationTrust{
+		TenantID:       tenantID,
+		SourceTenantID: sourceTenantID,
+		Weight:         *input.Weight,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "source_tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"weight", "updated_at"}),
+	}).Create(&trust).Error; err != nil {
+		return nil, fmt.Errorf("failed to set federation trust: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND source_tenant_id = ?", tenantID, sourceTenantID).First(&trust).Error; err != nil {
+		return nil, fmt.Errorf("failed to load federation trust: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "reputation.federation_trust_set",
+		ResourceType: stringPtr("reputation_federation_trust"),
+		ResourceID:   &trust.ID,
+		Metadata: map[string]interface{}{
+			"source_tenant_id": sourceTenantID,
+			"weight":           trust.Weight,
+		},
+	})
+	s.invalidateReaders(ctx, s.db.Where("tenant_id = ? AND source_tenant_id = ?", tenantID, sourceTenantID))
+
+	return &trust, nil
+}
+
+// ListFederationTrust returns the tenant's source weights
+func (s *Service) ListFederationTrust(ctx context.Context, tenantID uuid.UUID) ([]models.ReputationFederationTrust, error) {
+	var trust []models.ReputationFederationTrust
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at ASC").Find(&trust).Error; err != nil {
+		return nil, fmt.Errorf("failed to list federation trust: %w", err)
+	}
+	return trust, nil
+}
+
+// DeleteFederationTrust stops scores from a source tenant counting
+func (s *Service) DeleteFederationTrust(ctx context.Context, tenantID uuid.UUID, sourceTenantID uuid.UUID) (bool, error) {
+	result := s.db.WithContext(ctx).Where("tenant_id = ? AND source_tenant_id = ?", tenantID, sourceTenantID).Delete(&models.ReputationFederationTrust{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete federation trust: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		s.invalidateReaders(ctx, s.db.Where("tenant_id = ? AND source_tenant_id = ?", tenantID, sourceTenantID))
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// loadFederated adds the scores of linked subjects at trusted source tenants.
+// Links whose consent was revoked, expired or erased are skipped. Source
+// scores use the source tenant's active policy over its own records only, so
+// federation is never transitive. Each read is audited at both tenants.
+func (s *Service) loadFederated(ctx context.Context, tenantID uuid.UUID, inputs map[string]*ScoreInputs) error {
+	subjectIndexes := make([]string, 0, len(inputs))
+	for index := range inputs {
+		subjectIndexes = append(subjectIndexes, index)
+	}
+
+	var links []models.ReputationFederationLink
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ?", tenantID, subjectIndexes).
+		Order("created_at ASC").Find(&links).Error; err != nil {
+		return fmt.Errorf("failed to fetch federation links: %w", err)
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	var trust []models.ReputationFederationTrust
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND weight > 0", tenantID).Find(&trust).Error; err != nil {
+		return fmt.Errorf("failed to fetch federation trust: %w", err)
+	}
+	weights := make(map[uuid.UUID]float64, len(trust))
+	for _, t := range trust {
+		weights[t.SourceTenantID] = t.Weight
+	}
+
+	tokenIDs := make([]uuid.UUID, 0, len(links))
+	for _, link := range links {
+		tokenIDs = append(tokenIDs, link.ConsentTokenID)
+	}
+	grants, err := s.federationGrants(ctx, tokenIDs)
+	if err != nil {
+		return err
+	}
+
+	// Group readable links by source tenant so each source is scored in one batch
+	now := time.Now()
+	bySource := make(map[uuid.UUID][]models.ReputationFederationLink)
+	var sources []uuid.UUID
+	for _, link := range links {
+		grant, ok := grants[link.ConsentTokenID]
+		if _, trusted := weights[link.SourceTenantID]; !trusted || !ok ||
+			grant.Token.TenantID != link.SourceTenantID || !grant.allows(s, tenantID, link.SourceSubjectIndex, now) {
+			continue
+		}
+		if bySource[link.SourceTenantID] == nil {
+			sources = append(sources, link.SourceTenantID)
+		}
+		bySource[link.SourceTenantID] = append(bySource[link.SourceTenantID], link)
+	}
+
+	for _, sourceTenantID := range sources {
+		sourceLinks := bySource[sourceTenantID]
+		strategy, _, err := s.activePolicy(ctx, sourceTenantID)
+		if err != nil {
+			return err
+		}
+		sourceIndexes := make([]string, len(sourceLinks))
+		for i, link := range sourceLinks {
+			sourceIndexes[i] = link.SourceSubjectIndex
+		}
+		sourceInputs, err := s.loadLocalInputs(ctx, sourceTenantID, sourceIndexes)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range sourceLinks {
+			score, _ := strategy.Score(sourceInputs[link.SourceSubjectIndex], now)
+			federated := FederatedScore{
+				LinkID:         link.ID,
+				SourceTenantID: sourceTenantID,
+				Score:          score,
+				Level:          strategy.Level(score),
+				Weight:         weights[sourceTenantID],
+			}
+			in := inputs[link.SubjectIndex]
+			in.Federated = append(in.Federated, federated)
+
+			subjectID, err := s.crypto.Decrypt(ctx, tenantID, link.SubjectID)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt federation link: %w", err)
+			}
+			s.logFederation(ctx, "reputation.federated_read", &link, subjectID, grants[link.ConsentTokenID].SubjectID, map[string]interface{}{
+				"score": federated.Score,
+				"level": federated.Level,
+			})
+		}
+	}
+	return nil
+}
+
+// federationGrants loads federation consent tokens by ID. Tokens of another
+// scope or with malformed parties are left out.
+func (s *Service) federationGrants(ctx context.Context, tokenIDs []uuid.UUID) (map[uuid.UUID]*federationGrant, error) {
+	var tokens []models.ConsentToken
+	if err := s.db.WithContext(ctx).Where("id IN ? AND scope = ?", tokenIDs, consent.ScopeReputationFederation).
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch federation consents: %w", err)
+	}
+	if len(tokens) == 0 {
+		return map[uuid.UUID]*federationGrant{}, nil
+	}
+
+	var rows []struct {
+		ID    uuid.UUID
+		Party string
+	}
+	if err := s.db.WithContext(ctx).Raw("SELECT id, unnest(parties) AS party FROM consent_tokens WHERE id IN ?", tokenIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch consent parties: %w", err)
+	}
+	parties := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		parties[row.ID] = append(parties[row.ID], row.Party)
+	}
+
+	grants := make(map[uuid.UUID]*federationGrant, len(tokens))
+	for _, token := range tokens {
+		subjectID, readers, err := consent.FederationParties(token.TenantID, parties[token.ID])
+		if err != nil {
+			continue
+		}
+		grants[token.ID] = &federationGrant{Token: token, SubjectID: subjectID, Readers: readers}
+	}
+	return grants, nil
+}
+
+// logFederation records a federation event at the reading tenant and at the
+// source tenant, each against its own subject
+func (s *Service) logFederation(ctx context.Context, eventType string, link *models.ReputationFederationLink, subjectID string, sourceSubjectID string, metadata map[string]interface{}) {
+	readerMetadata := map[string]interface{}{"source_tenant_id": link.SourceTenantID, "consent_token_id": link.ConsentTokenID}
+	sourceMetadata := map[string]interface{}{"reader_tenant_id": link.TenantID, "consent_token_id": link.ConsentTokenID}
+	for key, value := range metadata {
+		readerMetadata[key] = value
+		sourceMetadata[key] = value
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     link.TenantID,
+		EventType:    eventType,
+		SubjectID:    &subjectID,
+		ResourceType: stringPtr("reputation_federation_link"),
+		ResourceID:   &link.ID,
+		Metadata:     readerMetadata,
+	})
+
+	input := audit.LogEventInput{
+		TenantID:     link.SourceTenantID,
+		EventType:    eventType,
+		ResourceType: stringPtr("reputation_federation_link"),
+		ResourceID:   &link.ID,
+		Metadata:     sourceMetadata,
+	}
+	if sourceSubjectID != "" {
+		input.SubjectID = &sourceSubjectID
+	}
+	s.audit.LogEvent(ctx, input)
+}
+
+// invalidateFederated drops the cached scores that read a changed subject from
+// another tenant
+func (s *Service) invalidateFederated(ctx context.Context, sourceTenantID uuid.UUID, sourceSubjectID string) {
+	s.invalidateReaders(ctx, s.db.Where("source_tenant_id = ? AND source_subject_index = ?",
+		sourceTenantID, s.crypto.BlindIndex(sourceTenantID, sourceSubjectID)))
+}
+
+// handleConsentEvent drops the cached scores that read through a consent
+// token once it is revoked
+func (s *Service) handleConsentEvent(ctx context.Context, event events.Event) {
+	if event.ResourceID == nil {
+		return
+	}
+	s.invalidateReaders(ctx, s.db.Where("consent_token_id = ?", *event.ResourceID))
+}
+
+// invalidateReaders drops the cached scores of the reading subjects of the
+// federation links matched by scope
+func (s *Service) invalidateReaders(ctx context.Context, scope *gorm.DB) {
+	var links []models.ReputationFederationLink
+	if err := scope.WithContext(ctx).Find(&links).Error; err != nil {
+		log.Printf("Failed to load federation links for invalidation: %v", err)
+		return
+	}
+	for _, link := range links {
+		subjectID, err := s.crypto.Decrypt(ctx, link.TenantID, link.SubjectID)
+		if err != nil {
+			log.Printf("Failed to decrypt federation link: %v", err)
+			continue
+		}
+		s.invalidate(ctx, link.TenantID, subjectID)
+	}
+}
@@ -1,10 +1,15 @@
 package reputation
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Handler manages reputation-related HTTP endpoints
@@ -41,3 +46,750 @@ func (h *Handler) GetReputation(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// RecordSignal handles POST /v1/reputation/signals
+// The idempotency key may be sent in the body or the Idempotency-Key header.
+func (h *Handler) RecordSignal(c *gin.Context) {
+	var input RecordSignalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+	if input.IdempotencyKey == "" {
+		input.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	}
+	if input.IdempotencyKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Idempotency key is required",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	signal, created, err := h.service.RecordSignal(c.Request.Context(), tenantID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_signal",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if !created {
+		statusCode = http.StatusOK // Idempotent replay
+	}
+
+	c.JSON(statusCode, signal)
+}
+
+// CreatePolicy handles POST /v1/scoring-policies
+func (h *Handler) CreatePolicy(c *gin.Context) {
+	var input CreatePolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	policy, err := h.service.CreatePolicy(c.Request.Context(), tenantID, input)
+	if err != nil {
+		policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// ListPolicies handles GET /v1/scoring-policies
+func (h *Handler) ListPolicies(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	policies, err := h.service.ListPolicies(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+// GetPolicy handles GET /v1/scoring-policies/:id
+func (h *Handler) GetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Policy")
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	policy, err := h.service.GetPolicy(c.Request.Context(), tenantID, id)
+	if err != nil {
+		policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// CreatePolicyVersion handles POST /v1/scoring-policies/:id/versions
+func (h *Handler) CreatePolicyVersion(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Policy")
+	if !ok {
+		return
+	}
+
+	var input CreatePolicyVersionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	version, err := h.service.CreatePolicyVersion(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, version)
+}
+
+// ActivatePolicy handles POST /v1/scoring-policies/:id/activate
+func (h *Handler) ActivatePolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Policy")
+	if !ok {
+		return
+	}
+
+	var input ActivatePolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	policy, err := h.service.ActivatePolicy(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// PreviewScore handles POST /v1/scoring-policies/:id/preview
+func (h *Handler) PreviewScore(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Policy")
+	if !ok {
+		return
+	}
+
+	var input PreviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	result, err := h.service.PreviewScore(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// policyError maps service errors to responses
+func policyError(c *gin.Context, err error) {
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Scoring policy not found",
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "policy_failed",
+		"message": err.Error(),
+	})
+}
+
+// GetHistory handles GET /v1/reputation/:subject/history?from=&to=&bucket=
+// The range defaults to the last 30 days in daily buckets.
+func (h *Handler) GetHistory(c *gin.Context) {
+	input := HistoryInput{
+		To:     time.Now(),
+		Bucket: c.DefaultQuery("bucket", "day"),
+	}
+	input.From = input.To.AddDate(0, 0, -30)
+
+	for param, target := range map[string]*time.Time{"from": &input.From, "to": &input.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": param + " must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		*target = parsed
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	history, err := h.service.GetHistory(c.Request.Context(), tenantID, c.Param("subject"), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "history_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Explain handles GET /v1/reputation/:subject/explain
+func (h *Handler) Explain(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	explanation, err := h.service.Explain(c.Request.Context(), tenantID, c.Param("subject"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "reputation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// GetReputationBatch handles POST /v1/reputation/batch
+// Per-subject failures are reported inline, so the batch itself returns 200.
+func (h *Handler) GetReputationBatch(c *gin.Context) {
+	var input BatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	items, err := h.service.GetReputationBatch(c.Request.Context(), tenantID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "batch_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": items})
+}
+
+// FileAppeal handles POST /v1/reputation/signals/:id/appeals
+func (h *Handler) FileAppeal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Signal")
+	if !ok {
+		return
+	}
+
+	var input FileAppealInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	appeal, err := h.service.FileAppeal(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		appealError(c, err, "Signal not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// ListAppeals handles GET /v1/reputation/appeals?status=&subject_id=
+func (h *Handler) ListAppeals(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	appeals, total, err := h.service.ListAppeals(c.Request.Context(), tenantID, ListAppealsInput{
+		Status:    c.Query("status"),
+		SubjectID: c.Query("subject_id"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appeals": appeals,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// GetAppeal handles GET /v1/reputation/appeals/:id
+func (h *Handler) GetAppeal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Appeal")
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	appeal, err := h.service.GetAppeal(c.Request.Context(), tenantID, id)
+	if err != nil {
+		appealError(c, err, "Appeal not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// ResolveAppeal handles POST /v1/reputation/appeals/:id/resolve
+func (h *Handler) ResolveAppeal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Appeal")
+	if !ok {
+		return
+	}
+
+	var input ResolveAppealInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	appeal, err := h.service.ResolveAppeal(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		appealError(c, err, "Appeal not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// parseUUIDParam reads the :id path parameter, naming the resource in the error
+func parseUUIDParam(c *gin.Context, resource string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": resource + " ID must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// appealError maps service errors to responses
+func appealError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": notFound,
+		})
+	case errors.Is(err, ErrAppealConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "appeal_conflict",
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "appeal_failed",
+			"message": err.Error(),
+		})
+	}
+}
+
+// CreateWatch handles POST /v1/reputation/watches
+func (h *Handler) CreateWatch(c *gin.Context) {
+	var input CreateWatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	watch, err := h.service.CreateWatch(c.Request.Context(), tenantID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_watch",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, watch)
+}
+
+// ListWatches handles GET /v1/reputation/watches
+func (h *Handler) ListWatches(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	watches, err := h.service.ListWatches(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watches": watches})
+}
+
+// DeleteWatch handles DELETE /v1/reputation/watches/:id
+func (h *Handler) DeleteWatch(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Watch")
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	deleted, err := h.service.DeleteWatch(c.Request.Context(), tenantID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "delete_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Watch not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAnomalies handles GET /v1/reputation/anomalies
+func (h *Handler) ListAnomalies(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	anomalies, total, err := h.service.ListAnomalies(c.Request.Context(), tenantID, ListAnomaliesInput{
+		Status:    c.Query("status"),
+		Kind:      c.Query("kind"),
+		SubjectID: c.Query("subject_id"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anomalies": anomalies,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// GetAnomaly handles GET /v1/reputation/anomalies/:id
+func (h *Handler) GetAnomaly(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Anomaly")
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	anomaly, err := h.service.GetAnomaly(c.Request.Context(), tenantID, id)
+	if err != nil {
+		anomalyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// DismissAnomaly handles POST /v1/reputation/anomalies/:id/dismiss
+func (h *Handler) DismissAnomaly(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "Anomaly")
+	if !ok {
+		return
+	}
+
+	var input DismissAnomalyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	anomaly, err := h.service.DismissAnomaly(c.Request.Context(), tenantID, id, input)
+	if err != nil {
+		anomalyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// anomalyError maps service errors to responses
+func anomalyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Anomaly not found",
+		})
+	case errors.Is(err, ErrAnomalyConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "anomaly_conflict",
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "anomaly_failed",
+			"message": err.Error(),
+		})
+	}
+}
+
+// LinkFederation handles POST /v1/subjects/:id/federation
+func (h *Handler) LinkFederation(c *gin.Context) {
+	var input LinkFederationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	link, err := h.service.LinkFederation(c.Request.Context(), tenantID, c.Param("id"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFederationConsent):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "consent_required",
+				"message": err.Error(),
+			})
+		case errors.Is(err, ErrFederationConflict):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "federation_conflict",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "federation_failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListFederationLinks handles GET /v1/subjects/:id/federation
+func (h *Handler) ListFederationLinks(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	links, err := h.service.ListFederationLinks(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// UnlinkFederation handles DELETE /v1/subjects/:id/federation/:link_id
+func (h *Handler) UnlinkFederation(c *gin.Context) {
+	linkID, err := uuid.Parse(c.Param("link_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Link ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	deleted, err := h.service.UnlinkFederation(c.Request.Context(), tenantID, c.Param("id"), linkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "delete_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Federation link not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetFederationTrust handles PUT /v1/reputation/federation/trust/:tenant_id
+func (h *Handler) SetFederationTrust(c *gin.Context) {
+	sourceTenantID, ok := parseSourceTenant(c)
+	if !ok {
+		return
+	}
+
+	var input SetFederationTrustInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	trust, err := h.service.SetFederationTrust(c.Request.Context(), tenantID, sourceTenantID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_trust",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, trust)
+}
+
+// ListFederationTrust handles GET /v1/reputation/federation/trust
+func (h *Handler) ListFederationTrust(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	trust, err := h.service.ListFederationTrust(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trust": trust})
+}
+
+// DeleteFederationTrust handles DELETE /v1/reputation/federation/trust/:tenant_id
+func (h *Handler) DeleteFederationTrust(c *gin.Context) {
+	sourceTenantID, ok := parseSourceTenant(c)
+	if !ok {
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	deleted, err := h.service.DeleteFederationTrust(c.Request.Context(), tenantID, sourceTenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "delete_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Federation trust not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseSourceTenant reads the :tenant_id path parameter
+func parseSourceTenant(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("tenant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Tenant ID must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
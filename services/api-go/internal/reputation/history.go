@@ -0,0 +1,134 @@
+package reputation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HistoryBuckets maps supported bucket sizes to their date_trunc unit
+var HistoryBuckets = map[string]string{
+	"hour":  "hour",
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+// maxHistoryRange bounds a history query
+const maxHistoryRange = 366 * 24 * time.Hour
+
+// HistoryInput represents a history query
+type HistoryInput struct {
+	From   time.Time
+	To     time.Time
+	Bucket string
+}
+
+// HistoryPoint summarises the snapshots recorded within one bucket
+type HistoryPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Score       float64   `json:"score"` // Last score recorded in the bucket
+	Level       string    `json:"level"`
+	MinScore    float64   `json:"min_score"`
+	MaxScore    float64   `json:"max_score"`
+	Snapshots   int       `json:"snapshots"`
+}
+
+// History is a subject's bucketed score history
+type History struct {
+	SubjectID string         `json:"subject_id"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Bucket    string         `json:"bucket"`
+	Points    []HistoryPoint `json:"points"` // Buckets without snapshots are omitted
+}
+
+// Explanation is a human-readable breakdown of a subject's current score
+type Explanation struct {
+	SubjectID   string    `json:"subject_id"`
+	Score       float64   `json:"score"`
+	Level       string    `json:"level"`
+	Policy      PolicyRef `json:"policy"`
+	Factors     []Factor  `json:"factors"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// GetHistory returns the subject's score snapshots grouped into buckets.
+// Snapshots are only recorded when the score changes, so an empty bucket
+// means the previous score still held.
+func (s *Service) GetHistory(ctx context.Context, tenantID uuid.UUID, subjectID string, input HistoryInput) (*History, error) {
+	unit, ok := HistoryBuckets[input.Bucket]
+	if !ok {
+		return nil, fmt.Errorf("bucket '%s' not supported", input.Bucket)
+	}
+	if !input.From.Before(input.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if input.To.Sub(input.From) > maxHistoryRange {
+		return nil, fmt.Errorf("range must not exceed %d days", int(maxHistoryRange.Hours()/24))
+	}
+
+	history := History{
+		SubjectID: subjectID,
+		From:      input.From,
+		To:        input.To,
+		Bucket:    input.Bucket,
+		Points:    []HistoryPoint{},
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT date_trunc(?, created_at) AS bucket_start,
+			(array_agg(score ORDER BY created_at DESC))[1] AS score,
+			(array_agg(level ORDER BY created_at DESC))[1] AS level,
+			MIN(score) AS min_score,
+			MAX(score) AS max_score,
+			COUNT(*) AS snapshots
+		FROM reputation_scores
+		WHERE tenant_id = ? AND subject_index = ? AND created_at >= ? AND created_at < ?
+		GROUP BY 1
+		ORDER BY 1`, unit, tenantID, s.crypto.BlindIndex(tenantID, subjectID), input.From, input.To).
+		Scan(&history.Points).Error; err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	return &history, nil
+}
+
+// Explain breaks the subject's score under the active policy down into its
+// factors and the verifications, signals (with any appeals) and badges behind them
+func (s *Service) Explain(ctx context.Context, tenantID uuid.UUID, subjectID string) (*Explanation, error) {
+	scorer, policy, err := s.activePolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := s.loadInputs(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	score, _, factors := scorer.ExplainAt(inputs, time.Now())
+
+	// Show where each signal stands in the appeal workflow
+	appeals, err := s.appealRefs(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range factors {
+		for j := range factors[i].Contributions {
+			c := &factors[i].Contributions[j]
+			if c.Kind == "signal" {
+				c.Appeal = appeals[c.ID]
+			}
+		}
+	}
+
+	return &Explanation{
+		SubjectID:   subjectID,
+		Score:       score,
+		Level:       scorer.Level(score),
+		Policy:      policy,
+		Factors:     factors,
+		GeneratedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,327 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreatePolicyInput represents input for creating a scoring policy
+type CreatePolicyInput struct {
+	Name        string          `json:"name" binding:"required"`
+	Description *string         `json:"description"`
+	Config      json.RawMessage `json:"config" binding:"required"` // Omitted fields keep their defaults
+}
+
+// CreatePolicyVersionInput represents input for a new policy version
+type CreatePolicyVersionInput struct {
+	Config json.RawMessage `json:"config" binding:"required"` // Omitted fields keep their defaults
+}
+
+// ActivatePolicyInput selects the version to score with; defaults to the latest
+type ActivatePolicyInput struct {
+	Version *int `json:"version"`
+}
+
+// PreviewInput represents a dry-run score request
+type PreviewInput struct {
+	SubjectID string `json:"subject_id" binding:"required"`
+	Version   *int   `json:"version"` // Defaults to the latest version
+}
+
+// PolicyDetail is a policy with its immutable versions
+type PolicyDetail struct {
+	models.ScoringPolicy
+	Versions []models.ScoringPolicyVersion `json:"versions"`
+}
+
+// PolicyRef identifies the policy a score was calculated under
+type PolicyRef struct {
+	ID      *uuid.UUID `json:"id"` // Nil for the built-in default policy
+	Name    string     `json:"name"`
+	Version int        `json:"version"`
+}
+
+// defaultPolicyRef describes the built-in policy used when none is active
+var defaultPolicyRef = PolicyRef{Name: "default", Version: 0}
+
+// CreatePolicy creates a policy with its first version
+func (s *Service) CreatePolicy(ctx context.Context, tenantID uuid.UUID, input CreatePolicyInput) (*PolicyDetail, error) {
+	config, err := encodeConfig(input.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := models.ScoringPolicy{
+		TenantID:      tenantID,
+		Name:          input.Name,
+		Description:   input.Description,
+		LatestVersion: 1,
+	}
+	version := models.ScoringPolicyVersion{
+		TenantID:  tenantID,
+		Version:   1,
+		Config:    config,
+		CreatedAt: time.Now(),
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&policy).Error; err != nil {
+			return fmt.Errorf("failed to create policy: %w", err)
+		}
+		version.PolicyID = policy.ID
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to create policy version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logPolicyEvent(ctx, tenantID, "reputation.policy_created", policy.ID, 1)
+	return &PolicyDetail{ScoringPolicy: policy, Versions: []models.ScoringPolicyVersion{version}}, nil
+}
+
+// CreatePolicyVersion appends a new immutable version to a policy.
+// An active policy keeps scoring with its pinned version until re-activated.
+func (s *Service) CreatePolicyVersion(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, input CreatePolicyVersionInput) (*models.ScoringPolicyVersion, error) {
+	config, err := encodeConfig(input.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var version models.ScoringPolicyVersion
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var policy models.ScoringPolicy
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("id = ? AND tenant_id = ?", policyID, tenantID).First(&policy).Error; err != nil {
+			return err
+		}
+
+		version = models.ScoringPolicyVersion{
+			PolicyID:  policy.ID,
+			TenantID:  tenantID,
+			Version:   policy.LatestVersion + 1,
+			Config:    config,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to create policy version: %w", err)
+		}
+		return tx.Model(&models.ScoringPolicy{}).Where("id = ?", policy.ID).
+			Update("latest_version", version.Version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logPolicyEvent(ctx, tenantID, "reputation.policy_version_created", policyID, version.Version)
+	return &version, nil
+}
+
+// ActivatePolicy makes a policy version the one used for the tenant's scores
+func (s *Service) ActivatePolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, input ActivatePolicyInput) (*models.ScoringPolicy, error) {
+	var policy models.ScoringPolicy
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND tenant_id = ?", policyID, tenantID).First(&policy).Error; err != nil {
+			return err
+		}
+		version := policy.LatestVersion
+		if input.Version != nil {
+			version = *input.Version
+		}
+		if version < 1 || version > policy.LatestVersion {
+			return fmt.Errorf("policy has no version %d", version)
+		}
+
+		if err := tx.Model(&models.ScoringPolicy{}).
+			Where("tenant_id = ? AND is_active = ? AND id <> ?", tenantID, true, policyID).
+			Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to deactivate previous policy: %w", err)
+		}
+		if err := tx.Model(&models.ScoringPolicy{}).Where("id = ?", policyID).Updates(map[string]interface{}{
+			"is_active":      true,
+			"active_version": version,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to activate policy: %w", err)
+		}
+		if err := tx.Create(&models.ScoringPolicyActivation{
+			TenantID:    tenantID,
+			PolicyID:    policyID,
+			Version:     version,
+			ActivatedAt: time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record policy activation: %w", err)
+		}
+		policy.IsActive = true
+		policy.ActiveVersion = &version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cached scores carry the policy version they were calculated under and
+	// are recalculated on the next read
+	s.logPolicyEvent(ctx, tenantID, "reputation.policy_activated", policyID, *policy.ActiveVersion)
+	return &policy, nil
+}
+
+// ListPolicies returns the tenant's policies
+func (s *Service) ListPolicies(ctx context.Context, tenantID uuid.UUID) ([]models.ScoringPolicy, error) {
+	var policies []models.ScoringPolicy
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetPolicy retrieves a policy with all of its versions
+func (s *Service) GetPolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID) (*PolicyDetail, error) {
+	var detail PolicyDetail
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", policyID, tenantID).First(&detail.ScoringPolicy).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Where("policy_id = ?", policyID).Order("version ASC").Find(&detail.Versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load policy versions: %w", err)
+	}
+	return &detail, nil
+}
+
+// PreviewScore calculates a subject's score under a policy version without
+// caching or recording a snapshot
+func (s *Service) PreviewScore(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, input PreviewInput) (*ReputationResult, error) {
+	var policy models.ScoringPolicy
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", policyID, tenantID).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	version := policy.LatestVersion
+	if input.Version != nil {
+		version = *input.Version
+	}
+
+	scorer, err := s.loadPolicyVersion(ctx, policy.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculate(ctx, tenantID, input.SubjectID, scorer, PolicyRef{ID: &policy.ID, Name: policy.Name, Version: version})
+}
+
+// activePolicy returns the scorer for the tenant's active policy, falling
+// back to the built-in default
+func (s *Service) activePolicy(ctx context.Context, tenantID uuid.UUID) (*Scorer, PolicyRef, error) {
+	var policy models.ScoringPolicy
+	err := s.db.WithContext(ctx).Where("tenant_id = ? AND is_active = ?", tenantID, true).First(&policy).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && policy.ActiveVersion == nil) {
+		return s.scorer, defaultPolicyRef, nil
+	}
+	if err != nil {
+		return nil, PolicyRef{}, fmt.Errorf("failed to load active policy: %w", err)
+	}
+
+	scorer, err := s.loadPolicyVersion(ctx, policy.ID, *policy.ActiveVersion)
+	if err != nil {
+		return nil, PolicyRef{}, err
+	}
+	return scorer, PolicyRef{ID: &policy.ID, Name: policy.Name, Version: *policy.ActiveVersion}, nil
+}
+
+// policyAsOf returns the scorer for the policy version that was active at a
+// past time, falling back to the built-in default before any activation
+func (s *Service) policyAsOf(ctx context.Context, tenantID uuid.UUID, at time.Time) (*Scorer, PolicyRef, error) {
+	var activation models.ScoringPolicyActivation
+	err := s.db.WithContext(ctx).Where("tenant_id = ? AND activated_at <= ?", tenantID, at).
+		Order("activated_at DESC").First(&activation).Error
+	if err == gorm.ErrRecordNotFound {
+		return s.scorer, defaultPolicyRef, nil
+	}
+	if err != nil {
+		return nil, PolicyRef{}, fmt.Errorf("failed to load policy activations: %w", err)
+	}
+
+	var policy models.ScoringPolicy
+	if err := s.db.WithContext(ctx).Where("id = ?", activation.PolicyID).First(&policy).Error; err != nil {
+		return nil, PolicyRef{}, fmt.Errorf("failed to load policy: %w", err)
+	}
+	scorer, err := s.loadPolicyVersion(ctx, policy.ID, activation.Version)
+	if err != nil {
+		return nil, PolicyRef{}, err
+	}
+	return scorer, PolicyRef{ID: &policy.ID, Name: policy.Name, Version: activation.Version}, nil
+}
+
+// loadPolicyVersion decodes a stored policy version into a scorer
+func (s *Service) loadPolicyVersion(ctx context.Context, policyID uuid.UUID, version int) (*Scorer, error) {
+	var stored models.ScoringPolicyVersion
+	if err := s.db.WithContext(ctx).Where("policy_id = ? AND version = ?", policyID, version).First(&stored).Error; err != nil {
+		return nil, fmt.Errorf("policy version %d not found", version)
+	}
+	// Versions stored before a field existed get its default, so they keep
+	// scoring the way they did when activated
+	return decodeConfig(json.RawMessage(stored.Config))
+}
+
+func (s *Service) logPolicyEvent(ctx context.Context, tenantID uuid.UUID, eventType string, policyID uuid.UUID, version int) {
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    eventType,
+		ResourceType: stringPtr("scoring_policy"),
+		ResourceID:   &policyID,
+		Metadata: map[string]interface{}{
+			"version": version,
+		},
+	})
+}
+
+// decodeConfig decodes a policy config over the default scorer. Fields the
+// config omits keep their defaults; a signal_half_life_days map replaces the
+// default rather than merging with it.
+func decodeConfig(raw json.RawMessage) (*Scorer, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("invalid policy config: %w", err)
+	}
+	config := NewScorer()
+	if _, ok := fields["signal_half_life_days"]; ok {
+		config.SignalHalfLifeDays = nil
+	}
+	if err := json.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("invalid policy config: %w", err)
+	}
+	return config, nil
+}
+
+// encodeConfig decodes a policy config over the default scorer, validates
+// it and serialises it for storage
+func encodeConfig(raw json.RawMessage) (string, error) {
+	config, err := decodeConfig(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if err := config.Validate(); err != nil {
+		return "", fmt.Errorf("invalid policy config: %w", err)
+	}
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal policy config: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// ActiveLevelBands returns the level bands of the tenant's active policy,
+// highest first
+func (s *Service) ActiveLevelBands(ctx context.Context, tenantID uuid.UUID) ([]LevelBand, error) {
+	scorer, _, err := s.activePolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return scorer.LevelBands, nil
+}
@@ -0,0 +1,97 @@
+package reputation
+
+import (
+	"context"
+	"log"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
+	"github.com/google/uuid"
+)
+
+// invalidatingEvents change an input of the subject's score
+var invalidatingEvents = []string{
+	"persona.verified",
+	"persona.expired",
+	"reputation.signal_recorded",
+	"link.badge_awarded",
+	"reputation.appeal_overturned",
+}
+
+// recomputeQueueSize bounds pending eager recomputes; overflow is recalculated on read
+const recomputeQueueSize = 1024
+
+type recomputeRequest struct {
+	tenantID  uuid.UUID
+	subjectID string
+}
+
+// Subscribe registers cache invalidation for score-changing events
+func (s *Service) Subscribe(bus *events.Bus) {
+	for _, eventType := range invalidatingEvents {
+		bus.Subscribe(eventType, s.HandleEvent)
+	}
+	bus.Subscribe("consent.revoked", s.handleConsentEvent)
+}
+
+// HandleEvent drops the cached score of the event's subject, and of subjects
+// at other tenants reading it through federation. A subject whose score was
+// cached was read within the cache TTL; such hot subjects are recomputed
+// eagerly so their next read is served fresh from cache.
+func (s *Service) HandleEvent(ctx context.Context, event events.Event) {
+	if event.SubjectID == nil {
+		return
+	}
+	s.invalidate(ctx, event.TenantID, *event.SubjectID)
+	s.invalidateFederated(ctx, event.TenantID, *event.SubjectID)
+}
+
+// invalidate drops a subject's cached score and queues hot subjects for recompute
+func (s *Service) invalidate(ctx context.Context, tenantID uuid.UUID, subjectID string) {
+	key := s.cacheKey(tenantID, subjectID)
+
+	deleted, err := s.redisClient.Del(ctx, key).Result()
+	if err != nil {
+		log.Printf("Failed to invalidate reputation: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A recompute already running may have read the inputs before this event
+	if deleted == 0 && !s.running[key] {
+		return
+	}
+	if s.queued[key] {
+		return
+	}
+	select {
+	case s.recompute <- recomputeRequest{tenantID: tenantID, subjectID: subjectID}:
+		s.queued[key] = true
+	default:
+		// Queue full; the next read recalculates
+	}
+}
+
+// Worker recomputes hot subjects queued by HandleEvent
+func (s *Service) Worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-s.recompute:
+			key := s.cacheKey(req.tenantID, req.subjectID)
+			s.mu.Lock()
+			delete(s.queued, key)
+			s.running[key] = true
+			s.mu.Unlock()
+
+			if _, err := s.GetReputation(ctx, req.tenantID, req.subjectID); err != nil {
+				log.Printf("Failed to recompute reputation: %v", err)
+			}
+
+			s.mu.Lock()
+			delete(s.running, key)
+			s.mu.Unlock()
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package reputation
 
 import (
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
@@ -13,14 +16,32 @@ type ScoreComponents struct {
 	AccountAge       int `json:"account_age_bonus"`
 	History          int `json:"history_bonus"` // or penalty
 	Signals          int `json:"signals_bonus"` // or penalty
+	Federated        int `json:"federated_bonus"`
 }
 
-// Scorer implements the deterministic scoring algorithm
+// LevelBand maps the lowest score of a band to its level name
+type LevelBand struct {
+	Level    string  `json:"level"`
+	MinScore float64 `json:"min_score"`
+}
+
+// Scorer implements the deterministic scoring algorithm.
+// Its configuration doubles as the body of a scoring policy version.
 type Scorer struct {
 	// Configuration for weights
-	VerifiedWeight    int
-	AgeWeightPerMonth int
-	MaxAgeBonus       int
+	VerifiedWeight    int            `json:"verified_weight"`
+	AgeWeightPerMonth int            `json:"age_weight_per_month"`
+	MaxAgeBonus       int            `json:"max_age_bonus"`
+	MinSignals        int            `json:"min_signals"`
+	MaxSignals        int            `json:"max_signals"`
+	BadgeWeight       int            `json:"badge_weight"`
+	MaxHistoryBonus   int            `json:"max_history_bonus"`
+	SignalWeights     map[string]int `json:"signal_weights,omitempty"` // Overrides the weight stored with each signal
+	LevelBands        []LevelBand    `json:"level_bands"`
+	// Signals of these types lose half their weight every N days
+	SignalHalfLifeDays map[string]float64 `json:"signal_half_life_days,omitempty"`
+	// Points a perfect score at a fully trusted federated tenant adds; also the cap
+	FederatedWeight int `json:"federated_weight"`
 }
 
 // NewScorer creates a default scorer
@@ -29,57 +50,330 @@ func NewScorer() *Scorer {
 		VerifiedWeight:    40,
 		AgeWeightPerMonth: 1, // 1 point per month
 		MaxAgeBonus:       30, // Cap age bonus at 30 points (2.5 years)
+		MinSignals:        -20,
+		MaxSignals:        30,
+		BadgeWeight:       2,  // 2 points per distinct linked subject
+		MaxHistoryBonus:   10, // Cap history bonus at 5 distinct links
+		FederatedWeight:   20,
+		SignalHalfLifeDays: map[string]float64{
+			string(SignalNoShow):       90,
+			string(SignalReportUpheld): 365,
+		},
+		LevelBands: []LevelBand{
+			{Level: "Very High", MinScore: 80},
+			{Level: "High", MinScore: 60},
+			{Level: "Medium", MinScore: 40},
+			{Level: "Low", MinScore: 0},
+		},
 	}
 }
 
+// Validate checks that a scorer configuration is usable and orders its level bands
+func (s *Scorer) Validate() error {
+	if s.VerifiedWeight < 0 || s.VerifiedWeight > 100 {
+		return fmt.Errorf("verified_weight must be between 0 and 100")
+	}
+	if s.AgeWeightPerMonth < 0 || s.MaxAgeBonus < 0 || s.MaxAgeBonus > 100 {
+		return fmt.Errorf("age weights must be between 0 and 100")
+	}
+	if s.MinSignals > 0 || s.MaxSignals < 0 || s.MinSignals < -100 || s.MaxSignals > 100 {
+		return fmt.Errorf("signal band must contain 0 and stay within -100..100")
+	}
+	if s.BadgeWeight < 0 || s.MaxHistoryBonus < 0 || s.MaxHistoryBonus > 100 {
+		return fmt.Errorf("history weights must be between 0 and 100")
+	}
+	if s.FederatedWeight < 0 || s.FederatedWeight > 100 {
+		return fmt.Errorf("federated_weight must be between 0 and 100")
+	}
+	for signalType := range s.SignalWeights {
+		if _, ok := SignalWeights[SignalType(signalType)]; !ok {
+			return fmt.Errorf("signal type '%s' not supported", signalType)
+		}
+	}
+	for signalType, days := range s.SignalHalfLifeDays {
+		if _, ok := SignalWeights[SignalType(signalType)]; !ok {
+			return fmt.Errorf("signal type '%s' not supported", signalType)
+		}
+		if days <= 0 {
+			return fmt.Errorf("signal half-life must be positive")
+		}
+	}
+	return validateLevelBands(s.LevelBands)
+}
+
+// validateLevelBands checks level bands and sorts them highest first
+func validateLevelBands(bands []LevelBand) error {
+	if len(bands) == 0 {
+		return fmt.Errorf("at least one level band is required")
+	}
+
+	sort.Slice(bands, func(i, j int) bool { return bands[i].MinScore > bands[j].MinScore })
+	seen := make(map[string]bool)
+	for _, band := range bands {
+		if band.Level == "" || seen[band.Level] {
+			return fmt.Errorf("level names must be unique and non-empty")
+		}
+		if band.MinScore < 0 || band.MinScore > 100 {
+			return fmt.Errorf("level band min_score must be between 0 and 100")
+		}
+		seen[band.Level] = true
+	}
+	if bands[len(bands)-1].MinScore != 0 {
+		return fmt.Errorf("the lowest level band must start at 0")
+	}
+	return nil
+}
+
+// Level returns the name of the band a score falls in
+func (s *Scorer) Level(score float64) string {
+	for _, band := range s.LevelBands {
+		if score >= band.MinScore {
+			return band.Level
+		}
+	}
+	return s.LevelBands[len(s.LevelBands)-1].Level
+}
+
+// Factor explains one component of a score
+type Factor struct {
+	Name          string         `json:"name"` // verification, account_age, signals, history, federation
+	Points        int            `json:"points"`
+	Description   string         `json:"description"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// Contribution is a record that fed into a factor
+type Contribution struct {
+	Kind       string     `json:"kind"` // verification, signal, badge, federated
+	ID         string     `json:"id"`
+	Label      string     `json:"label"`
+	Weight     int        `json:"weight"`              // Points this record adds on its own
+	Counted    bool       `json:"counted"`             // False when the record is ignored, e.g. expired
+	Decay      float64    `json:"decay,omitempty"`     // Fraction of Weight left after time decay
+	Dampening  *float64   `json:"dampening,omitempty"` // Fraction of Weight kept after collusion dampening
+	Note       string     `json:"note,omitempty"`
+	Appeal     *AppealRef `json:"appeal,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// AppealRef is the appeal filed against a signal
+type AppealRef struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // open, upheld, overturned
+}
+
+// signalWeight is the weight a signal counts at: the policy's override for
+// its type, or the weight stored with it
+func (s *Scorer) signalWeight(sig *models.ReputationSignal) int {
+	if override, ok := s.SignalWeights[sig.SignalType]; ok {
+		return override
+	}
+	return sig.Weight
+}
+
+// Name identifies the scorer as a ScoringStrategy
+func (s *Scorer) Name() string {
+	return StrategyWeighted
+}
+
+// Score implements ScoringStrategy
+func (s *Scorer) Score(inputs *ScoreInputs, at time.Time) (float64, ScoreComponents) {
+	score, components, _ := s.ExplainAt(inputs, at)
+	return score, components
+}
+
 // CalculateScore computes the reputation score for a subject
-func (s *Scorer) CalculateScore(verifications []models.PersonaVerification, history []interface{}) (float64, ScoreComponents) {
+func (s *Scorer) CalculateScore(verifications []models.PersonaVerification, signals []models.ReputationSignal, badges []models.TrustBadge) (float64, ScoreComponents) {
+	score, components, _ := s.Explain(verifications, signals, badges)
+	return score, components
+}
+
+// Explain computes the score along with the records behind each component
+func (s *Scorer) Explain(verifications []models.PersonaVerification, signals []models.ReputationSignal, badges []models.TrustBadge) (float64, ScoreComponents, []Factor) {
+	return s.ExplainAt(&ScoreInputs{Verifications: verifications, Signals: signals, Badges: badges}, time.Now())
+}
+
+// ExplainAt is Explain evaluated at a given time, for replaying past states.
+// Positive signals and badges from counterparts in inputs.Dampening are
+// scaled down by the collusion analyzer's factor.
+func (s *Scorer) ExplainAt(inputs *ScoreInputs, now time.Time) (float64, ScoreComponents, []Factor) {
+	verifications, signals, badges := inputs.Verifications, inputs.Signals, inputs.Badges
 	components := ScoreComponents{
 		BaseScore: 0,
 	}
 
 	// 1. Verification Status (+40)
 	// Check if any verification is active and verified
+	verification := Factor{Name: "verification", Contributions: []Contribution{}}
 	isVerified := false
 	var earliestVerification *time.Time
 
 	for _, v := range verifications {
+		c := Contribution{
+			Kind:       "verification",
+			ID:         v.ID.String(),
+			Label:      v.Provider + " verification (" + v.Status + ")",
+			OccurredAt: v.CreatedAt,
+		}
 		if v.Status == "verified" {
 			// Check expiration
-			if v.ExpiresAt == nil || v.ExpiresAt.After(time.Now()) {
+			if v.ExpiresAt == nil || v.ExpiresAt.After(now) {
 				isVerified = true
+				c.Counted = true
+				c.Weight = s.VerifiedWeight
 				if earliestVerification == nil || v.CreatedAt.Before(*earliestVerification) {
 					createdAt := v.CreatedAt
 					earliestVerification = &createdAt
 				}
+			} else {
+				c.Note = "expired"
 			}
+		} else {
+			c.Note = "not verified"
 		}
+		verification.Contributions = append(verification.Contributions, c)
 	}
 
 	if isVerified {
 		components.Verification = s.VerifiedWeight
+		verification.Description = fmt.Sprintf("Holds an active verification: +%d", s.VerifiedWeight)
+	} else {
+		verification.Description = "No active verification"
 	}
+	verification.Points = components.Verification
 
 	// 2. Account Age (0-30 verification age)
 	// For API MVP, we might rely on the earliest verification date as "account age" in our system
 	// unless we are passed an account created_at date from the tenant.
 	// Let's assume age starts from first verification for now.
+	accountAge := Factor{Name: "account_age", Contributions: []Contribution{}, Description: "No active verification to date the account from"}
 	if earliestVerification != nil {
-		age := time.Since(*earliestVerification)
+		age := now.Sub(*earliestVerification)
 		months := int(age.Hours() / 24 / 30)
 		ageBonus := months * s.AgeWeightPerMonth
 		if ageBonus > s.MaxAgeBonus {
 			ageBonus = s.MaxAgeBonus
 		}
 		components.AccountAge = ageBonus
+		accountAge.Description = fmt.Sprintf("%d months since first active verification at %d per month, capped at %d", months, s.AgeWeightPerMonth, s.MaxAgeBonus)
 	}
+	accountAge.Points = components.AccountAge
 
 	// 3. Dispute Signals / History (-20 to +30)
-	// TODO: Signal integration. For M3 MVP, we assume neutral (0).
-	components.Signals = 0
+	// Overturned signals are skipped; the rest decay with their type's half-life
+	signalFactor := Factor{Name: "signals", Contributions: []Contribution{}}
+	signalTotal := 0.0
+	counted := 0
+	for _, sig := range signals {
+		weight := s.signalWeight(&sig)
+		c := Contribution{
+			Kind:       "signal",
+			ID:         sig.ID.String(),
+			Label:      sig.SignalType + " from " + sig.SourceType,
+			Weight:     weight,
+			OccurredAt: sig.OccurredAt,
+		}
+		if sig.Status == "overturned" {
+			c.Note = "overturned on appeal"
+			signalFactor.Contributions = append(signalFactor.Contributions, c)
+			continue
+		}
+
+		decay := 1.0
+		if halfLife, ok := s.SignalHalfLifeDays[sig.SignalType]; ok && sig.OccurredAt.Before(now) {
+			decay = math.Pow(0.5, now.Sub(sig.OccurredAt).Hours()/24/halfLife)
+			c.Decay = decay
+			c.Note = fmt.Sprintf("half-life %g days", halfLife)
+		}
+		if factor, ok := inputs.dampening(sig.SourceIndex); ok && weight > 0 {
+			decay *= factor
+			c.Dampening = &factor
+			c.Note = joinNote(c.Note, "source flagged by collusion analysis")
+		}
+		c.Counted = true
+		signalTotal += float64(weight) * decay
+		counted++
+		signalFactor.Contributions = append(signalFactor.Contributions, c)
+	}
+	rawSignals := int(math.Round(signalTotal))
+	signalPoints := rawSignals
+	if signalPoints < s.MinSignals {
+		signalPoints = s.MinSignals
+	}
+	if signalPoints > s.MaxSignals {
+		signalPoints = s.MaxSignals
+	}
+	components.Signals = signalPoints
+	signalFactor.Points = signalPoints
+	signalFactor.Description = fmt.Sprintf("%d counted signals summing to %d after decay, clamped to %d..%d", counted, rawSignals, s.MinSignals, s.MaxSignals)
+
+	// 4. Link History (0-10)
+	// Only distinct counterparts count, so repeated links with one partner add nothing
+	history := Factor{Name: "history", Contributions: []Contribution{}}
+	linked := make(map[string]bool)
+	historyTotal := 0.0
+	for _, b := range badges {
+		counterpart := "badge:" + b.ID.String() // Counterpart erased
+		if b.LinkedSubjectIndex != nil {
+			counterpart = *b.LinkedSubjectIndex
+		}
+		c := Contribution{
+			Kind:       "badge",
+			ID:         b.ID.String(),
+			Label:      "trust badge from link " + b.LinkID.String(),
+			OccurredAt: b.AwardedAt,
+		}
+		if linked[counterpart] {
+			c.Note = "repeat counterpart"
+		} else {
+			c.Counted = true
+			c.Weight = s.BadgeWeight
+			factor := 1.0
+			if damp, ok := inputs.dampening(b.LinkedSubjectIndex); ok {
+				factor = damp
+				c.Dampening = &damp
+				c.Note = "counterpart flagged by collusion analysis"
+			}
+			historyTotal += float64(s.BadgeWeight) * factor
+		}
+		linked[counterpart] = true
+		history.Contributions = append(history.Contributions, c)
+	}
+	historyBonus := int(math.Round(historyTotal))
+	if historyBonus > s.MaxHistoryBonus {
+		historyBonus = s.MaxHistoryBonus
+	}
+	components.History = historyBonus
+	history.Points = historyBonus
+	history.Description = fmt.Sprintf("%d distinct link counterparts at %d each, capped at %d", len(linked), s.BadgeWeight, s.MaxHistoryBonus)
+
+	// 5. Federated Reputation (0-20)
+	// Scores at other tenants, scaled by the tenant's trust in each source
+	federation := Factor{Name: "federation", Contributions: []Contribution{}}
+	federatedTotal := 0.0
+	for _, f := range inputs.Federated {
+		points := f.Score / 100 * f.Weight * float64(s.FederatedWeight)
+		federatedTotal += points
+		federation.Contributions = append(federation.Contributions, Contribution{
+			Kind:       "federated",
+			ID:         f.LinkID.String(),
+			Label:      f.Level + " at tenant " + f.SourceTenantID.String(),
+			Weight:     int(math.Round(points)),
+			Counted:    true,
+			Note:       fmt.Sprintf("score %.2f at trust %g", f.Score, f.Weight),
+			OccurredAt: now,
+		})
+	}
+	federatedBonus := int(math.Round(federatedTotal))
+	if federatedBonus > s.FederatedWeight {
+		federatedBonus = s.FederatedWeight
+	}
+	components.Federated = federatedBonus
+	federation.Points = federatedBonus
+	federation.Description = fmt.Sprintf("%d consented scores at other tenants, up to %d points each scaled by trust, capped at %d", len(inputs.Federated), s.FederatedWeight, s.FederatedWeight)
 
 	// Calculate Total
-	total := components.BaseScore + components.Verification + components.AccountAge + components.History + components.Signals
+	total := components.BaseScore + components.Verification + components.AccountAge + components.History + components.Signals + components.Federated
 
 	// Cap filter (0-100)
 	if total < 0 {
@@ -89,5 +383,12 @@ func (s *Scorer) CalculateScore(verifications []models.PersonaVerification, hist
 		total = 100
 	}
 
-	return float64(total), components
+	return float64(total), components, []Factor{verification, accountAge, signalFactor, history, federation}
+}
+
+func joinNote(note, addition string) string {
+	if note == "" {
+		return addition
+	}
+	return note + "; " + addition
 }
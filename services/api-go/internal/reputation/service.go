@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -18,16 +20,27 @@ type Service struct {
 	db          *gorm.DB
 	redisClient *redis.Client
 	audit       *audit.Logger
+	crypto      *encryption.Service
 	scorer      *Scorer
+
+	// Eager recompute state, keyed by cache key
+	recompute chan recomputeRequest
+	mu        sync.Mutex
+	queued    map[string]bool
+	running   map[string]bool
 }
 
 // NewService creates a new reputation service
-func NewService(db *gorm.DB, redisClient *redis.Client, audit *audit.Logger) *Service {
+func NewService(db *gorm.DB, redisClient *redis.Client, audit *audit.Logger, crypto *encryption.Service) *Service {
 	return &Service{
 		db:          db,
 		redisClient: redisClient,
 		audit:       audit,
+		crypto:      crypto,
 		scorer:      NewScorer(),
+		recompute:   make(chan recomputeRequest, recomputeQueueSize),
+		queued:      make(map[string]bool),
+		running:     make(map[string]bool),
 	}
 }
 
@@ -35,89 +48,236 @@ func NewService(db *gorm.DB, redisClient *redis.Client, audit *audit.Logger) *Se
 type ReputationResult struct {
 	SubjectID      string          `json:"subject_id"`
 	Score          float64         `json:"score"`
-	Level          string          `json:"level"` // Low, Medium, High, Very High
+	Level          string          `json:"level"` // Named by the policy's level bands
 	Components     ScoreComponents `json:"components"`
+	Policy         PolicyRef       `json:"policy"`
 	LastCalculated time.Time       `json:"last_calculated"`
 	Cached         bool            `json:"cached"`
 }
 
 // GetReputation retrieves or calculates the reputation score for a subject
+// under the tenant's active scoring policy
 func (s *Service) GetReputation(ctx context.Context, tenantID uuid.UUID, subjectID string) (*ReputationResult, error) {
-	cacheKey := fmt.Sprintf("reputation:%s:%s", tenantID, subjectID)
+	cacheKey := s.cacheKey(tenantID, subjectID)
+
+	scorer, policy, err := s.activePolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
 
-	// 1. Check Cache
+	// 1. Check Cache (scores calculated under another policy version are stale)
 	val, err := s.redisClient.Get(ctx, cacheKey).Result()
 	if err == nil {
 		var result ReputationResult
-		if err := json.Unmarshal([]byte(val), &result); err == nil {
+		if err := json.Unmarshal([]byte(val), &result); err == nil && samePolicy(result.Policy, policy) {
 			result.Cached = true
 			return &result, nil
 		}
 	}
 
-	// 2. Fetch Data
+	// 2. Calculate Score
+	result, err := s.calculate(ctx, tenantID, subjectID, scorer, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Cache Result (1 Hour TTL)
+	jsonBytes, _ := json.Marshal(result)
+	s.redisClient.Set(ctx, cacheKey, jsonBytes, 1*time.Hour)
+
+	// 4. Persist Score Snapshot
+	if errs := s.recordSnapshots(ctx, tenantID, []*ReputationResult{result}); errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	return result, nil
+}
+
+// recordSnapshots appends each score to its subject's history unless it
+// repeats the subject's latest snapshot, then reports level changes and
+// threshold crossings against that snapshot. Errors are returned per result.
+func (s *Service) recordSnapshots(ctx context.Context, tenantID uuid.UUID, results []*ReputationResult) []error {
+	errs := make([]error, len(results))
+	indexes := make([]string, len(results))
+	for i, result := range results {
+		indexes[i] = s.crypto.BlindIndex(tenantID, result.SubjectID)
+	}
+
+	var latest []models.ReputationScore
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (subject_index) *
+		FROM reputation_scores
+		WHERE tenant_id = ? AND subject_index IN ?
+		ORDER BY subject_index, created_at DESC`, tenantID, indexes).
+		Scan(&latest).Error; err != nil {
+		for i := range errs {
+			errs[i] = fmt.Errorf("failed to load latest snapshot: %w", err)
+		}
+		return errs
+	}
+	latestByIndex := make(map[string]models.ReputationScore, len(latest))
+	for _, snapshot := range latest {
+		latestByIndex[snapshot.SubjectIndex] = snapshot
+	}
+
+	var snapshots []models.ReputationScore
+	var pending []int
+	var changes []scoreChange
+	for i, result := range results {
+		prev, ok := latestByIndex[indexes[i]]
+		if ok && prev.Score == result.Score && prev.Level == result.Level &&
+			prev.PolicyVersion != nil && samePolicy(PolicyRef{ID: prev.PolicyID, Version: *prev.PolicyVersion}, result.Policy) {
+			continue
+		}
+
+		encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, result.SubjectID)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to encrypt subject: %w", err)
+			continue
+		}
+		policyVersion := result.Policy.Version
+		snapshots = append(snapshots, models.ReputationScore{
+			ID:            uuid.New(),
+			TenantID:      tenantID,
+			SubjectID:     encryptedSubject,
+			SubjectIndex:  indexes[i],
+			Score:         result.Score,
+			Level:         result.Level,
+			Factors:       string(convertMapToJSON(map[string]interface{}{"components": result.Components})),
+			PolicyID:      result.Policy.ID,
+			PolicyVersion: &policyVersion,
+			CalculatedAt:  result.LastCalculated,
+			CreatedAt:     result.LastCalculated,
+			UpdatedAt:     result.LastCalculated,
+		})
+		pending = append(pending, i)
+		if ok {
+			changes = append(changes, scoreChange{Previous: prev, Current: result})
+		}
+	}
+
+	if len(snapshots) > 0 {
+		if err := s.db.WithContext(ctx).Create(&snapshots).Error; err != nil {
+			for _, i := range pending {
+				errs[i] = fmt.Errorf("failed to record snapshot: %w", err)
+			}
+			return errs
+		}
+	}
+
+	s.notifyChanges(ctx, tenantID, changes)
+	return errs
+}
+
+// loadInputs fetches a subject's score inputs
+func (s *Service) loadInputs(ctx context.Context, tenantID uuid.UUID, subjectID string) (*ScoreInputs, error) {
+	subjectIndex := s.crypto.BlindIndex(tenantID, subjectID)
+	inputs, err := s.loadInputsBatch(ctx, tenantID, []string{subjectIndex})
+	if err != nil {
+		return nil, err
+	}
+	return inputs[subjectIndex], nil
+}
+
+// loadInputsBatch fetches the score inputs of several subjects, including
+// their consented scores at other tenants
+func (s *Service) loadInputsBatch(ctx context.Context, tenantID uuid.UUID, subjectIndexes []string) (map[string]*ScoreInputs, error) {
+	inputs, err := s.loadLocalInputs(ctx, tenantID, subjectIndexes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadFederated(ctx, tenantID, inputs); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// loadLocalInputs fetches the tenant's own score inputs of several subjects with
+// one query per table, keyed by blind index (subject_id is encrypted, so look up by index)
+func (s *Service) loadLocalInputs(ctx context.Context, tenantID uuid.UUID, subjectIndexes []string) (map[string]*ScoreInputs, error) {
+	inputs := make(map[string]*ScoreInputs, len(subjectIndexes))
+	for _, index := range subjectIndexes {
+		inputs[index] = &ScoreInputs{}
+	}
+
 	// Fetch Verifications
 	var verifications []models.PersonaVerification
-	if err := s.db.Where("tenant_id = ? AND subject_id = ?", tenantID, subjectID).Find(&verifications).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ?", tenantID, subjectIndexes).
+		Order("created_at ASC").Find(&verifications).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch verification history: %w", err)
 	}
+	for _, v := range verifications {
+		inputs[v.SubjectIndex].Verifications = append(inputs[v.SubjectIndex].Verifications, v)
+	}
 
-	// 3. Calculate Score
-	score, components := s.scorer.CalculateScore(verifications, nil)
+	// Fetch Signals
+	var signals []models.ReputationSignal
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ?", tenantID, subjectIndexes).
+		Order("occurred_at ASC").Find(&signals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reputation signals: %w", err)
+	}
+	for _, sig := range signals {
+		inputs[sig.SubjectIndex].Signals = append(inputs[sig.SubjectIndex].Signals, sig)
+	}
+
+	// Fetch Trust Badges
+	var badges []models.TrustBadge
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index IN ?", tenantID, subjectIndexes).
+		Order("awarded_at ASC").Find(&badges).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch trust badges: %w", err)
+	}
+	for _, b := range badges {
+		inputs[b.SubjectIndex].Badges = append(inputs[b.SubjectIndex].Badges, b)
+	}
 
-	// Determine Level
-	level := "Low"
-	if score >= 80 {
-		level = "Very High"
-	} else if score >= 60 {
-		level = "High"
-	} else if score >= 40 {
-		level = "Medium"
+	if err := s.loadDampening(ctx, tenantID, inputs, time.Now()); err != nil {
+		return nil, err
 	}
 
-	result := ReputationResult{
+	return inputs, nil
+}
+
+// calculate scores a subject with the given strategy, without caching or persisting
+func (s *Service) calculate(ctx context.Context, tenantID uuid.UUID, subjectID string, strategy ScoringStrategy, policy PolicyRef) (*ReputationResult, error) {
+	inputs, err := s.loadInputs(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return score(subjectID, inputs, strategy, policy), nil
+}
+
+// score builds a result from a subject's loaded inputs
+func score(subjectID string, inputs *ScoreInputs, strategy ScoringStrategy, policy PolicyRef) *ReputationResult {
+	now := time.Now()
+	total, components := strategy.Score(inputs, now)
+
+	return &ReputationResult{
 		SubjectID:      subjectID,
-		Score:          score,
-		Level:          level,
+		Score:          total,
+		Level:          strategy.Level(total),
 		Components:     components,
-		LastCalculated: time.Now(),
-		Cached:         false,
+		Policy:         policy,
+		LastCalculated: now,
 	}
+}
 
-	// 4. Cache Result (1 Hour TTL)
-	jsonBytes, _ := json.Marshal(result)
-	s.redisClient.Set(ctx, cacheKey, jsonBytes, 1*time.Hour)
+// samePolicy reports whether two policy references name the same version
+func samePolicy(a, b PolicyRef) bool {
+	if (a.ID == nil) != (b.ID == nil) {
+		return false
+	}
+	return a.Version == b.Version && (a.ID == nil || *a.ID == *b.ID)
+}
+
+// CacheKey returns the Redis key holding a subject's cached reputation. Keys
+// carry the subject's blind index rather than its identifier.
+func CacheKey(tenantID uuid.UUID, subjectIndex string) string {
+	return fmt.Sprintf("reputation:%s:%s", tenantID, subjectIndex)
+}
 
-	// 5. Persist Score Snapshot (optional but good for history)
-	// For M3, let's just create/update the reputation_scores table
-	// Upsert logic
-	snapshot := models.ReputationScore{
-		TenantID:  tenantID,
-		SubjectID: subjectID,
-		Score:     score,
-		Level:     level,
-		Factors:   string(convertMapToJSON(map[string]interface{}{"components": components})),
-		UpdatedAt: time.Now(),
-	}
-	
-	// Assuming unique constraint on (tenant_id, subject_id) in schema logic.
-	// Although the schema migration might not have unique constraint explicitly named?
-	// Let's replace or create.
-	// Actually, the initial schema.sql creates the table but might not enforce uniqueness on current entry.
-	// But it's better to log a new entry if we want history. 
-	// The schema says `reputation_scores` has an ID.
-	// Let's just create a new record for "current state" (or overwrite if we track "current").
-	// Implementation choice: Keep track of "latest" in a `current_reputation` or just append to `reputation_scores` as history log?
-	// Reviewing schema.sql: "CREATE TABLE reputation_scores" looks like a log with timestamp.
-	// So we append.
-	
-	// Wait, constant writes on every GET might be heavy if un-cached. 
-	// But we cache for 1 hour. So writes happen max once per hour per active user.
-	// Acceptable for MVP.
-	snapshot.ID = uuid.New()
-	s.db.Create(&snapshot)
-
-	return &result, nil
+func (s *Service) cacheKey(tenantID uuid.UUID, subjectID string) string {
+	return CacheKey(tenantID, s.crypto.BlindIndex(tenantID, subjectID))
 }
 
 func convertMapToJSON(m map[string]interface{}) []byte {
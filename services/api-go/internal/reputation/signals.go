@@ -0,0 +1,173 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SignalType identifies a kind of reputation signal
+type SignalType string
+
+const (
+	SignalEventAttended   SignalType = "event_attended"
+	SignalNoShow          SignalType = "no_show"
+	SignalReportUpheld    SignalType = "report_upheld"
+	SignalDisputeResolved SignalType = "dispute_resolved"
+	SignalEndorsement     SignalType = "endorsement"
+)
+
+// SignalWeights are the score points each signal type contributes.
+// The sum over all of a subject's signals is clamped to the Signals band.
+var SignalWeights = map[SignalType]int{
+	SignalEventAttended:   2,
+	SignalNoShow:          -5,
+	SignalReportUpheld:    -10,
+	SignalDisputeResolved: 3,
+	SignalEndorsement:     5,
+}
+
+// maxSignalClockSkew is how far in the future occurred_at may be, to allow
+// for the sender's clock running ahead. Later dates would escape time decay.
+const maxSignalClockSkew = 5 * time.Minute
+
+// SourceTypes lists who may attribute a signal
+var SourceTypes = map[string]bool{
+	"tenant":    true,
+	"subject":   true,
+	"moderator": true,
+	"system":    true,
+}
+
+// RecordSignalInput represents a reputation signal to ingest
+type RecordSignalInput struct {
+	SubjectID      string                 `json:"subject_id" binding:"required"`
+	SignalType     SignalType             `json:"signal_type" binding:"required"`
+	SourceType     string                 `json:"source_type"`
+	SourceID       *string                `json:"source_id"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	OccurredAt     *time.Time             `json:"occurred_at"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+// RecordSignal stores a signal. The recorded event invalidates the subject's cached score.
+// A repeated idempotency key returns the original signal with created=false.
+func (s *Service) RecordSignal(ctx context.Context, tenantID uuid.UUID, input RecordSignalInput) (*models.ReputationSignal, bool, error) {
+	weight, ok := SignalWeights[input.SignalType]
+	if !ok {
+		return nil, false, fmt.Errorf("signal type '%s' not supported", input.SignalType)
+	}
+	if input.SourceType == "" {
+		input.SourceType = "tenant"
+	}
+	if !SourceTypes[input.SourceType] {
+		return nil, false, fmt.Errorf("source type '%s' not supported", input.SourceType)
+	}
+	if input.IdempotencyKey == "" {
+		return nil, false, fmt.Errorf("idempotency key is required")
+	}
+	if input.OccurredAt != nil && input.OccurredAt.After(time.Now().Add(maxSignalClockSkew)) {
+		return nil, false, fmt.Errorf("occurred_at must not be in the future")
+	}
+
+	// Replays return the stored signal
+	var existing models.ReputationSignal
+	err := s.db.WithContext(ctx).Where("tenant_id = ? AND idempotency_key = ?", tenantID, input.IdempotencyKey).First(&existing).Error
+	if err == nil {
+		if err := s.openSignal(ctx, &existing); err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, false, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+
+	occurredAt := time.Now()
+	if input.OccurredAt != nil {
+		occurredAt = *input.OccurredAt
+	}
+	if input.Metadata == nil {
+		input.Metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(input.Metadata)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, input.SubjectID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	encryptedSource, err := s.crypto.EncryptPtr(ctx, tenantID, input.SourceID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encrypt source: %w", err)
+	}
+
+	signal := models.ReputationSignal{
+		TenantID:       tenantID,
+		SubjectID:      encryptedSubject,
+		SubjectIndex:   s.crypto.BlindIndex(tenantID, input.SubjectID),
+		SignalType:     string(input.SignalType),
+		Weight:         weight,
+		SourceType:     input.SourceType,
+		SourceID:       encryptedSource,
+		SourceIndex:    s.crypto.BlindIndexPtr(tenantID, input.SourceID),
+		IdempotencyKey: input.IdempotencyKey,
+		Metadata:       string(metadataJSON),
+		OccurredAt:     occurredAt,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&signal).Error; err != nil {
+		// Lost a race with a concurrent request using the same key
+		if err := s.db.WithContext(ctx).Where("tenant_id = ? AND idempotency_key = ?", tenantID, input.IdempotencyKey).First(&existing).Error; err == nil {
+			if err := s.openSignal(ctx, &existing); err != nil {
+				return nil, false, err
+			}
+			return &existing, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to store signal: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "reputation.signal_recorded",
+		SubjectID:    &input.SubjectID,
+		ResourceType: stringPtr("reputation_signal"),
+		ResourceID:   &signal.ID,
+		Metadata: map[string]interface{}{
+			"signal_type": signal.SignalType,
+			"weight":      signal.Weight,
+			"source_type": signal.SourceType,
+		},
+	})
+
+	signal.SubjectID = input.SubjectID
+	signal.SourceID = input.SourceID
+	return &signal, true, nil
+}
+
+// openSignal decrypts the identifiers of a stored signal in place
+func (s *Service) openSignal(ctx context.Context, signal *models.ReputationSignal) error {
+	subject, err := s.crypto.Decrypt(ctx, signal.TenantID, signal.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt signal subject: %w", err)
+	}
+	source, err := s.crypto.DecryptPtr(ctx, signal.TenantID, signal.SourceID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt signal source: %w", err)
+	}
+	signal.SubjectID = subject
+	signal.SourceID = source
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,219 @@
+package reputation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+)
+
+// Built-in strategy names
+const (
+	StrategyWeighted = "weighted"
+	StrategyBayesian = "bayesian"
+)
+
+// ScoreInputs are the records a subject's score is calculated from
+type ScoreInputs struct {
+	Verifications []models.PersonaVerification
+	Signals       []models.ReputationSignal
+	Badges        []models.TrustBadge
+	// Dampening maps counterpart blind indexes flagged by the collusion
+	// analyzer to the fraction of their positive contribution that counts
+	Dampening map[string]float64
+	// Federated are the subject's scores at other tenants, read under consent
+	Federated []FederatedScore
+}
+
+// dampening returns the factor for a counterpart, if it is flagged
+func (in *ScoreInputs) dampening(counterpartIndex *string) (float64, bool) {
+	if counterpartIndex == nil {
+		return 0, false
+	}
+	factor, ok := in.Dampening[*counterpartIndex]
+	return factor, ok
+}
+
+// ScoringStrategy turns a subject's records into a score and level.
+// Strategies must be deterministic for a given set of inputs and time, so
+// past scores can be replayed by the backtester.
+type ScoringStrategy interface {
+	Name() string
+	Score(inputs *ScoreInputs, at time.Time) (float64, ScoreComponents)
+	Level(score float64) string
+}
+
+// StrategyFactory builds a strategy from its JSON config; an empty config
+// selects the strategy's defaults
+type StrategyFactory func(config json.RawMessage) (ScoringStrategy, error)
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]StrategyFactory{
+		StrategyWeighted: newWeightedStrategy,
+		StrategyBayesian: newBayesianStrategy,
+	}
+)
+
+// RegisterStrategy makes a strategy available by name
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+}
+
+// NewStrategy builds a registered strategy
+func NewStrategy(name string, config json.RawMessage) (ScoringStrategy, error) {
+	strategiesMu.RLock()
+	factory, ok := strategies[name]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("scoring strategy '%s' not registered", name)
+	}
+	return factory(config)
+}
+
+// StrategyNames lists the registered strategies
+func StrategyNames() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newWeightedStrategy overlays a config on the default scorer
+func newWeightedStrategy(config json.RawMessage) (ScoringStrategy, error) {
+	scorer := NewScorer()
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, scorer); err != nil {
+			return nil, fmt.Errorf("invalid weighted config: %w", err)
+		}
+	}
+	if err := scorer.Validate(); err != nil {
+		return nil, err
+	}
+	return scorer, nil
+}
+
+// BayesianStrategy scores the mean of a Beta distribution over "good
+// interaction" evidence. Verifications, badges and positive signals add to
+// alpha, negative signals to beta, so subjects with little history stay
+// close to the prior instead of swinging on a single report.
+type BayesianStrategy struct {
+	PriorPositive     float64     `json:"prior_positive"`
+	PriorNegative     float64     `json:"prior_negative"`
+	VerifiedEvidence  float64     `json:"verified_evidence"`  // Added to alpha by an active verification
+	BadgeEvidence     float64     `json:"badge_evidence"`     // Per distinct link counterpart
+	SignalScale       float64     `json:"signal_scale"`       // Evidence per point of signal weight
+	HalfLifeDays      float64     `json:"half_life_days"`     // Applies to all signals; 0 disables decay
+	FederatedEvidence float64     `json:"federated_evidence"` // Per fully trusted source tenant, split by its score
+	LevelBands        []LevelBand `json:"level_bands"`
+}
+
+func newBayesianStrategy(config json.RawMessage) (ScoringStrategy, error) {
+	strategy := &BayesianStrategy{
+		PriorPositive:     2,
+		PriorNegative:     2,
+		VerifiedEvidence:  4,
+		BadgeEvidence:     1,
+		SignalScale:       0.2,
+		HalfLifeDays:      180,
+		FederatedEvidence: 4,
+		LevelBands:        NewScorer().LevelBands,
+	}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, strategy); err != nil {
+			return nil, fmt.Errorf("invalid bayesian config: %w", err)
+		}
+	}
+	if strategy.PriorPositive <= 0 || strategy.PriorNegative <= 0 {
+		return nil, fmt.Errorf("priors must be positive")
+	}
+	if strategy.VerifiedEvidence < 0 || strategy.BadgeEvidence < 0 || strategy.SignalScale < 0 || strategy.HalfLifeDays < 0 || strategy.FederatedEvidence < 0 {
+		return nil, fmt.Errorf("evidence weights must not be negative")
+	}
+	if err := validateLevelBands(strategy.LevelBands); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// Name implements ScoringStrategy
+func (b *BayesianStrategy) Name() string {
+	return StrategyBayesian
+}
+
+// Score implements ScoringStrategy. Components are left empty because the
+// score is not a sum of bonuses.
+func (b *BayesianStrategy) Score(inputs *ScoreInputs, at time.Time) (float64, ScoreComponents) {
+	alpha, beta := b.PriorPositive, b.PriorNegative
+
+	for _, v := range inputs.Verifications {
+		if v.Status == "verified" && (v.ExpiresAt == nil || v.ExpiresAt.After(at)) {
+			alpha += b.VerifiedEvidence
+			break
+		}
+	}
+
+	for _, sig := range inputs.Signals {
+		if sig.Status == "overturned" {
+			continue
+		}
+		evidence := math.Abs(float64(sig.Weight)) * b.SignalScale
+		if b.HalfLifeDays > 0 && sig.OccurredAt.Before(at) {
+			evidence *= math.Pow(0.5, at.Sub(sig.OccurredAt).Hours()/24/b.HalfLifeDays)
+		}
+		if sig.Weight >= 0 {
+			if factor, ok := inputs.dampening(sig.SourceIndex); ok {
+				evidence *= factor
+			}
+			alpha += evidence
+		} else {
+			beta += evidence
+		}
+	}
+
+	linked := make(map[string]bool)
+	for _, badge := range inputs.Badges {
+		counterpart := "badge:" + badge.ID.String()
+		if badge.LinkedSubjectIndex != nil {
+			counterpart = *badge.LinkedSubjectIndex
+		}
+		if linked[counterpart] {
+			continue
+		}
+		linked[counterpart] = true
+		factor := 1.0
+		if damp, ok := inputs.dampening(badge.LinkedSubjectIndex); ok {
+			factor = damp
+		}
+		alpha += b.BadgeEvidence * factor
+	}
+
+	// A source score of 80 adds 80% of the evidence to alpha and 20% to beta
+	for _, f := range inputs.Federated {
+		evidence := b.FederatedEvidence * f.Weight
+		alpha += evidence * f.Score / 100
+		beta += evidence * (1 - f.Score/100)
+	}
+
+	return math.Round(100*alpha/(alpha+beta)*100) / 100, ScoreComponents{}
+}
+
+// Level implements ScoringStrategy
+func (b *BayesianStrategy) Level(score float64) string {
+	for _, band := range b.LevelBands {
+		if score >= band.MinScore {
+			return band.Level
+		}
+	}
+	return b.LevelBands[len(b.LevelBands)-1].Level
+}
@@ -0,0 +1,155 @@
+package reputation
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// WatchDirections lists which crossings a watch reports
+var WatchDirections = map[string]bool{
+	"below": true, // Score falls under the threshold
+	"above": true, // Score reaches the threshold from under it
+	"both":  true,
+}
+
+// CreateWatchInput represents a threshold watch
+type CreateWatchInput struct {
+	SubjectID *string  `json:"subject_id"` // Omit to watch every subject
+	Threshold *float64 `json:"threshold" binding:"required"`
+	Direction string   `json:"direction"` // Defaults to below
+}
+
+// scoreChange is a recalculated score that differs from the subject's latest snapshot
+type scoreChange struct {
+	Previous models.ReputationScore
+	Current  *ReputationResult
+}
+
+// CreateWatch registers a threshold watch on one subject or on all subjects
+func (s *Service) CreateWatch(ctx context.Context, tenantID uuid.UUID, input CreateWatchInput) (*models.ReputationWatch, error) {
+	if *input.Threshold < 0 || *input.Threshold > 100 {
+		return nil, fmt.Errorf("threshold must be between 0 and 100")
+	}
+	if input.Direction == "" {
+		input.Direction = "below"
+	}
+	if !WatchDirections[input.Direction] {
+		return nil, fmt.Errorf("direction '%s' not supported", input.Direction)
+	}
+
+	encryptedSubject, err := s.crypto.EncryptPtr(ctx, tenantID, input.SubjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+	watch := models.ReputationWatch{
+		TenantID:     tenantID,
+		SubjectID:    encryptedSubject,
+		SubjectIndex: s.crypto.BlindIndexPtr(tenantID, input.SubjectID),
+		Threshold:    *input.Threshold,
+		Direction:    input.Direction,
+	}
+	if err := s.db.WithContext(ctx).Create(&watch).Error; err != nil {
+		return nil, fmt.Errorf("failed to create watch: %w", err)
+	}
+
+	watch.SubjectID = input.SubjectID
+	return &watch, nil
+}
+
+// ListWatches returns the tenant's watches
+func (s *Service) ListWatches(ctx context.Context, tenantID uuid.UUID) ([]models.ReputationWatch, error) {
+	var watches []models.ReputationWatch
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&watches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list watches: %w", err)
+	}
+	for i := range watches {
+		subject, err := s.crypto.DecryptPtr(ctx, tenantID, watches[i].SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt watch subject: %w", err)
+		}
+		watches[i].SubjectID = subject
+	}
+	return watches, nil
+}
+
+// DeleteWatch removes a watch
+func (s *Service) DeleteWatch(ctx context.Context, tenantID uuid.UUID, watchID uuid.UUID) (bool, error) {
+	result := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", watchID, tenantID).Delete(&models.ReputationWatch{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete watch: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// notifyChanges records reputation.level_changed for every level transition
+// and reputation.threshold_crossed for every watch a change crosses. The
+// events reach subscribed webhook endpoints through the event bus.
+func (s *Service) notifyChanges(ctx context.Context, tenantID uuid.UUID, changes []scoreChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	indexes := make([]string, len(changes))
+	for i, change := range changes {
+		indexes[i] = change.Previous.SubjectIndex
+	}
+	var watches []models.ReputationWatch
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND (subject_index IS NULL OR subject_index IN ?)", tenantID, indexes).
+		Find(&watches).Error; err != nil {
+		log.Printf("Failed to load reputation watches: %v", err)
+	}
+
+	for _, change := range changes {
+		prev, cur := change.Previous, change.Current
+		subjectID := cur.SubjectID
+
+		if prev.Level != cur.Level {
+			s.logChange(ctx, tenantID, subjectID, "reputation.level_changed", map[string]interface{}{
+				"previous_level": prev.Level,
+				"level":          cur.Level,
+				"previous_score": prev.Score,
+				"score":          cur.Score,
+				"policy":         cur.Policy,
+			})
+		}
+
+		for _, w := range watches {
+			if w.SubjectIndex != nil && *w.SubjectIndex != prev.SubjectIndex {
+				continue
+			}
+			fell := prev.Score >= w.Threshold && cur.Score < w.Threshold
+			rose := prev.Score < w.Threshold && cur.Score >= w.Threshold
+			if !(fell && w.Direction != "above") && !(rose && w.Direction != "below") {
+				continue
+			}
+			direction := "above"
+			if fell {
+				direction = "below"
+			}
+			s.logChange(ctx, tenantID, subjectID, "reputation.threshold_crossed", map[string]interface{}{
+				"watch_id":       w.ID,
+				"threshold":      w.Threshold,
+				"direction":      direction,
+				"previous_score": prev.Score,
+				"score":          cur.Score,
+				"level":          cur.Level,
+			})
+		}
+	}
+}
+
+func (s *Service) logChange(ctx context.Context, tenantID uuid.UUID, subjectID string, eventType string, metadata map[string]interface{}) {
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    eventType,
+		SubjectID:    &subjectID,
+		ResourceType: stringPtr("reputation_score"),
+		Metadata:     metadata,
+	})
+}
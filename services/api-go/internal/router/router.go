@@ -2,16 +2,25 @@ package router
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"os"
 
+	"github.com/dennislee928/mighty-eagle/api-go/internal/attestations"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/billing"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/consent"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/links"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/persona"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/persona/providers"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/pseudonym"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/reputation"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/storage"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/subjects"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -36,29 +45,102 @@ func SetupRouter(db *gorm.DB, redisClient *redis.Client) *gin.Engine {
 	})
 
 	// Initialize Services
-	auditLogger := audit.NewLogger(db)
+	keystore, err := encryption.NewLocalKeystore()
+	if err != nil {
+		log.Fatalf("Failed to initialize keystore: %v", err)
+	}
+	encryptionService, err := encryption.NewService(db, keystore)
+	if err != nil {
+		log.Fatalf("Failed to initialize encryption service: %v", err)
+	}
+	// Start re-encryption worker for key rotations
+	go encryptionService.Worker(context.Background())
+	encryptionHandler := encryption.NewHandler(encryptionService)
+
+	signer, err := signing.NewSigner()
+	if err != nil {
+		log.Fatalf("Failed to initialize signer: %v", err)
+	}
+
+	// In-process bus carrying domain events from the audit log to subscribers
+	eventBus := events.NewBus()
+	auditLogger := audit.NewLogger(db, encryptionService, eventBus)
 	billingService := billing.NewService(db, redisClient, auditLogger)
 	billingHandler := billing.NewHandler(billingService)
 
-	webhookService := webhooks.NewService(db)
+	webhookService := webhooks.NewService(db, encryptionService)
 	// Start webhook worker
 	go webhookService.Worker(context.Background())
+	// Deliver recorded events to subscribed endpoints
+	eventBus.Subscribe("*", webhookService.HandleEvent)
 	
-	personaService := persona.NewService(db, auditLogger, webhookService, billingService)
+	pseudonymService, err := pseudonym.NewService(db, encryptionService)
+	if err != nil {
+		log.Fatalf("Failed to initialize pseudonym service: %v", err)
+	}
+	pseudonymHandler := pseudonym.NewHandler(pseudonymService)
+
+	personaService := persona.NewService(db, auditLogger, billingService, encryptionService, pseudonymService)
 	personaService.RegisterProvider(providers.NewMockProvider())
 	if os.Getenv("WORLDID_APP_ID") != "" {
 		personaService.RegisterProvider(providers.NewWorldIDProvider())
 	}
+	// Start expiry worker for lapsed verifications
+	go personaService.Worker(context.Background())
 	personaHandler := persona.NewHandler(personaService)
 
 	consentService := consent.NewService(db, auditLogger)
 	consentHandler := consent.NewHandler(consentService)
 
-	reputationService := reputation.NewService(db, redisClient, auditLogger)
+	reputationService := reputation.NewService(db, redisClient, auditLogger, encryptionService)
+	// Invalidate cached scores on score-changing events and recompute hot subjects
+	reputationService.Subscribe(eventBus)
+	go reputationService.Worker(context.Background())
+	go reputationService.GraphWorker(context.Background())
 	reputationHandler := reputation.NewHandler(reputationService)
 
-	auditExporter := audit.NewExporter(db, auditLogger, billingService)
-	auditHandler := audit.NewHandler(auditExporter)
+	linkService := links.NewService(db, auditLogger, encryptionService, personaService)
+	// Start expiry worker for pending links
+	go linkService.Worker(context.Background())
+	linkHandler := links.NewHandler(linkService)
+
+	urlSigner, err := signing.NewURLSigner()
+	if err != nil {
+		log.Fatalf("Failed to initialize URL signer: %v", err)
+	}
+	subjectService := subjects.NewService(db, redisClient, auditLogger, encryptionService, signer, urlSigner, reputationService)
+	// Keep cached subject profiles in step with new events
+	eventBus.Subscribe("*", subjectService.HandleEvent)
+	// Start purge worker for expired access exports
+	go subjectService.Worker(context.Background())
+	subjectHandler := subjects.NewHandler(subjectService)
+
+	// Signed download links (authorised by signature, not API key)
+	r.GET("/downloads/subject-access-exports/:id", subjectHandler.DownloadAccessExport)
+
+	attestationService := attestations.NewService(db, auditLogger, encryptionService, reputationService, signer)
+	attestationHandler := attestations.NewHandler(attestationService)
+
+	// Relying parties verify attestations without an API key
+	r.GET("/.well-known/jwks.json", attestationHandler.JWKS)
+	r.POST("/attestations/verify", attestationHandler.Verify)
+
+	exportStore, err := storage.NewBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to initialize export storage: %v", err)
+	}
+	auditExporter := audit.NewExporter(db, auditLogger, billingService, exportStore, urlSigner, signer)
+	// Start export job runners and the purge of expired export files
+	go auditExporter.Worker(context.Background())
+	auditCheckpointer := audit.NewCheckpointer(db, signer)
+	go auditCheckpointer.Worker(context.Background())
+	auditStreamer := audit.NewStreamer(db, redisClient, auditLogger)
+	// Fan recorded events out to live audit streams on every replica
+	eventBus.Subscribe("*", auditStreamer.HandleEvent)
+	go auditStreamer.Worker(context.Background())
+	auditHandler := audit.NewHandler(auditExporter, auditLogger, auditCheckpointer, auditStreamer)
+	// Export downloads are authorised by signed links
+	r.GET("/downloads/audit-exports/:id", auditHandler.DownloadExport)
 
 	// API v1 routes
 	v1 := r.Group("/v1")
@@ -76,12 +158,72 @@ func SetupRouter(db *gorm.DB, redisClient *redis.Client) *gin.Engine {
 		v1.POST("/consent/tokens/:id/revoke", consentHandler.RevokeToken)
 		v1.GET("/consent/tokens/:id", consentHandler.GetToken)
 
+		// Consensual link routes
+		v1.POST("/links", linkHandler.CreateLink)
+		v1.GET("/links", linkHandler.ListLinks)
+		v1.GET("/links/:id", linkHandler.GetLink)
+		v1.POST("/links/:id/accept", linkHandler.AcceptLink)
+		v1.POST("/links/:id/confirm", linkHandler.ConfirmLink)
+		v1.POST("/links/:id/decline", linkHandler.DeclineLink)
+		v1.GET("/subjects/:id/badges", linkHandler.ListBadges)
+
+		// Pseudonym routes
+		v1.POST("/pseudonyms", pseudonymHandler.CreatePseudonym)
+		v1.GET("/pseudonyms/:pseudonym", pseudonymHandler.ResolvePseudonym)
+
+		// Data subject rights routes
+		v1.GET("/subjects/:id", subjectHandler.GetProfile)
+		v1.GET("/subjects/:id/verifications", subjectHandler.ListVerifications)
+		v1.DELETE("/subjects/:id", subjectHandler.DeleteSubject)
+		v1.GET("/subjects/:id/erasures/:job_id", subjectHandler.GetErasure)
+		v1.POST("/subjects/:id/access-exports", subjectHandler.CreateAccessExport)
+		v1.GET("/subjects/:id/access-exports/:export_id", subjectHandler.GetAccessExport)
+		v1.POST("/subjects/:id/attestations", attestationHandler.Issue)
+		v1.POST("/subjects/:id/federation", reputationHandler.LinkFederation)
+		v1.GET("/subjects/:id/federation", reputationHandler.ListFederationLinks)
+		v1.DELETE("/subjects/:id/federation/:link_id", reputationHandler.UnlinkFederation)
+
 		// Reputation routes
 		v1.GET("/reputation/:subject", reputationHandler.GetReputation)
+		v1.GET("/reputation/:subject/history", reputationHandler.GetHistory)
+		v1.GET("/reputation/:subject/explain", reputationHandler.Explain)
+		v1.POST("/reputation/signals", reputationHandler.RecordSignal)
+		v1.POST("/reputation/batch", reputationHandler.GetReputationBatch)
+		v1.POST("/reputation/signals/:id/appeals", reputationHandler.FileAppeal)
+		v1.GET("/reputation/appeals", reputationHandler.ListAppeals)
+		v1.GET("/reputation/appeals/:id", reputationHandler.GetAppeal)
+		v1.POST("/reputation/appeals/:id/resolve", reputationHandler.ResolveAppeal)
+		v1.POST("/reputation/watches", reputationHandler.CreateWatch)
+		v1.GET("/reputation/watches", reputationHandler.ListWatches)
+		v1.DELETE("/reputation/watches/:id", reputationHandler.DeleteWatch)
+		v1.GET("/reputation/anomalies", reputationHandler.ListAnomalies)
+		v1.GET("/reputation/anomalies/:id", reputationHandler.GetAnomaly)
+		v1.POST("/reputation/anomalies/:id/dismiss", reputationHandler.DismissAnomaly)
+		v1.GET("/reputation/federation/trust", reputationHandler.ListFederationTrust)
+		v1.PUT("/reputation/federation/trust/:tenant_id", reputationHandler.SetFederationTrust)
+		v1.DELETE("/reputation/federation/trust/:tenant_id", reputationHandler.DeleteFederationTrust)
+
+		// Scoring policy routes
+		v1.POST("/scoring-policies", reputationHandler.CreatePolicy)
+		v1.GET("/scoring-policies", reputationHandler.ListPolicies)
+		v1.GET("/scoring-policies/:id", reputationHandler.GetPolicy)
+		v1.POST("/scoring-policies/:id/versions", reputationHandler.CreatePolicyVersion)
+		v1.POST("/scoring-policies/:id/activate", reputationHandler.ActivatePolicy)
+		v1.POST("/scoring-policies/:id/preview", reputationHandler.PreviewScore)
 
 		// Audit Export routes
 		v1.POST("/audit/exports", billing.CheckEntitlementMiddleware(billingService, "exports"), auditHandler.CreateExport)
 		v1.GET("/audit/exports/:id", auditHandler.GetExport)
+		v1.DELETE("/audit/exports/:id", auditHandler.CancelExport)
+		v1.PUT("/audit/export-key", auditHandler.SetExportKey)
+		v1.GET("/audit/export-key", auditHandler.GetExportKey)
+		v1.DELETE("/audit/export-key", auditHandler.DeleteExportKey)
+		v1.GET("/audit/events", auditHandler.ListEvents)
+		v1.GET("/audit/stream", auditHandler.Stream)
+		v1.GET("/audit/verify", auditHandler.VerifyChain)
+		v1.GET("/audit/checkpoints", auditHandler.ListCheckpoints)
+		v1.GET("/audit/checkpoints/:id", auditHandler.GetCheckpoint)
+		v1.GET("/audit/events/:id/proof", auditHandler.GetEventProof)
 
 		// Webhook Management routes
 		webhookHandler := webhooks.NewHandler(webhookService)
@@ -91,6 +233,10 @@ func SetupRouter(db *gorm.DB, redisClient *redis.Client) *gin.Engine {
 		// Billing routes
 		v1.GET("/billing/usage", billingHandler.GetUsage)
 
+		// Encryption key management routes
+		v1.POST("/encryption/key-rotations", encryptionHandler.CreateKeyRotation)
+		v1.GET("/encryption/key-rotations/:id", encryptionHandler.GetKeyRotation)
+
 		// Placeholder: API info endpoint
 		v1.GET("/info", func(c *gin.Context) {
 			tenant, _ := middleware.GetTenant(c)
@@ -0,0 +1,143 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JWTAlgorithm is the JOSE name of Ed25519 signatures
+const JWTAlgorithm = "EdDSA"
+
+// JWTHeader is the protected header of a compact JWS
+type JWTHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid"`
+}
+
+// JWK is an Ed25519 public key in JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// JWKSet is the document published at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// SignJWT signs claims as a compact JWS with the server key
+func (s *Signer) SignJWT(typ string, claims interface{}) (string, error) {
+	header, err := json.Marshal(JWTHeader{Algorithm: JWTAlgorithm, Type: typ, KeyID: s.keyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal header: %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(s.privateKey, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyJWT checks a compact JWS against the published keys and returns its
+// header and raw payload. Expiry and other claims are left to the caller.
+func (s *Signer) VerifyJWT(token string) (*JWTHeader, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("malformed token")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed header")
+	}
+	var header JWTHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, nil, fmt.Errorf("malformed header")
+	}
+	if header.Algorithm != JWTAlgorithm {
+		return nil, nil, fmt.Errorf("unsupported algorithm '%s'", header.Algorithm)
+	}
+	publicKey, ok := s.publicKey(header.KeyID)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown key '%s'", header.KeyID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(publicKey, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, nil, fmt.Errorf("invalid signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed payload")
+	}
+	return &header, payload, nil
+}
+
+// JWKS returns the keys relying parties use to verify server tokens offline:
+// the current key first, then retired keys so tokens signed before a
+// rotation keep verifying until they expire
+func (s *Signer) JWKS() JWKSet {
+	ids := make([]string, 0, len(s.retired))
+	for id := range s.retired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JWKSet{Keys: []JWK{jwk(s.keyID, s.PublicKey())}}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, jwk(id, s.retired[id]))
+	}
+	return set
+}
+
+func (s *Signer) publicKey(keyID string) (ed25519.PublicKey, bool) {
+	if keyID == s.keyID {
+		return s.PublicKey(), true
+	}
+	key, ok := s.retired[keyID]
+	return key, ok
+}
+
+func jwk(keyID string, key ed25519.PublicKey) JWK {
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(key),
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: JWTAlgorithm,
+	}
+}
+
+// parseRetiredKeys reads "key_id:base64 public key" pairs
+func parseRetiredKeys(value, currentKeyID string) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || id == currentKeyID {
+			return nil, fmt.Errorf("invalid SIGNING_RETIRED_PUBLIC_KEYS entry '%s'", entry)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("SIGNING_RETIRED_PUBLIC_KEYS key '%s' must be a base64 encoded %d byte Ed25519 public key", id, ed25519.PublicKeySize)
+		}
+		keys[id] = ed25519.PublicKey(decoded)
+	}
+	return keys, nil
+}
@@ -0,0 +1,115 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Algorithm is the signature algorithm used for server signatures
+const Algorithm = "Ed25519"
+
+// Signature is a detached server signature over a document
+type Signature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"` // base64url, no padding
+}
+
+// Signer signs documents with the server's Ed25519 key.
+// The key is loaded from the environment:
+//
+//	SIGNING_PRIVATE_KEY          base64 encoded 32 byte Ed25519 seed
+//	SIGNING_KEY_ID               identifier published with the public key
+//	SIGNING_RETIRED_PUBLIC_KEYS  comma separated "key_id:base64 public key" pairs
+//	                             still published after a rotation
+type Signer struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+	retired    map[string]ed25519.PublicKey
+}
+
+// NewSigner creates a signer from environment configuration
+func NewSigner() (*Signer, error) {
+	keyID := os.Getenv("SIGNING_KEY_ID")
+	if keyID == "" {
+		keyID = "server-1"
+	}
+
+	encoded := os.Getenv("SIGNING_PRIVATE_KEY")
+	var seed []byte
+	if encoded == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("SIGNING_PRIVATE_KEY is required in release mode")
+		}
+		// Development fallback so the API can start without extra setup.
+		log.Println("⚠️  SIGNING_PRIVATE_KEY not set, using insecure development signing key")
+		devSeed := sha256.Sum256([]byte("mighty-eagle-dev-signing-key"))
+		seed = devSeed[:]
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIGNING_PRIVATE_KEY: %w", err)
+		}
+		if len(decoded) != ed25519.SeedSize {
+			return nil, fmt.Errorf("SIGNING_PRIVATE_KEY must be %d bytes, got %d", ed25519.SeedSize, len(decoded))
+		}
+		seed = decoded
+	}
+
+	retired, err := parseRetiredKeys(os.Getenv("SIGNING_RETIRED_PUBLIC_KEYS"), keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		keyID:      keyID,
+		privateKey: ed25519.NewKeyFromSeed(seed),
+		retired:    retired,
+	}, nil
+}
+
+// KeyID returns the identifier of the signing key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the public half of the signing key
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// Sign signs raw bytes
+func (s *Signer) Sign(payload []byte) Signature {
+	return Signature{
+		KeyID:     s.keyID,
+		Algorithm: Algorithm,
+		Value:     base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.privateKey, payload)),
+	}
+}
+
+// SignJSON marshals a document and signs the resulting bytes.
+// The marshalled bytes are returned so callers can store exactly what was signed.
+func (s *Signer) SignJSON(document interface{}) ([]byte, Signature, error) {
+	payload, err := json.Marshal(document)
+	if err != nil {
+		return nil, Signature{}, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	return payload, s.Sign(payload), nil
+}
+
+// Verify checks a signature produced by this signer
+func (s *Signer) Verify(payload []byte, signature Signature) bool {
+	if signature.KeyID != s.keyID || signature.Algorithm != Algorithm {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature.Value)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.PublicKey(), payload, sig)
+}
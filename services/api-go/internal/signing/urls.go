@@ -0,0 +1,67 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLSigner issues and checks expiring download links.
+// Links are signed with DOWNLOAD_URL_SECRET and prefixed with API_BASE_URL when set.
+type URLSigner struct {
+	secret  []byte
+	baseURL string
+}
+
+// NewURLSigner creates a URL signer from environment configuration
+func NewURLSigner() (*URLSigner, error) {
+	secret := os.Getenv("DOWNLOAD_URL_SECRET")
+	if secret == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("DOWNLOAD_URL_SECRET is required in release mode")
+		}
+		log.Println("⚠️  DOWNLOAD_URL_SECRET not set, using insecure development secret")
+		secret = "mighty-eagle-dev-download-secret"
+	}
+	return &URLSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(os.Getenv("API_BASE_URL"), "/"),
+	}, nil
+}
+
+// Sign returns the path with "expires" and "signature" query parameters
+func (u *URLSigner) Sign(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", u.mac(path, expires))
+	return u.baseURL + path + "?" + query.Encode()
+}
+
+// Verify checks the signature and expiry of a signed path
+func (u *URLSigner) Verify(path, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry")
+	}
+	if !hmac.Equal([]byte(u.mac(path, expires)), []byte(signature)) {
+		return fmt.Errorf("invalid signature")
+	}
+	if time.Now().Unix() > expiresAt {
+		return fmt.Errorf("link expired")
+	}
+	return nil
+}
+
+func (u *URLSigner) mac(path, expires string) string {
+	h := hmac.New(sha256.New, u.secret)
+	h.Write([]byte(path + "\n" + expires))
+	return hex.EncodeToString(h.Sum(nil))
+}
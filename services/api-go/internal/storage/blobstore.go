@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores large generated files such as audit exports.
+// Keys are slash-separated paths chosen by the caller.
+type BlobStore interface {
+	// Put stores size bytes read from body under key, replacing any blob there
+	Put(ctx context.Context, key string, body io.ReaderAt, size int64, contentType string) error
+	// Open streams a blob; the caller must close it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore creates the blob store selected by EXPORT_STORAGE_BACKEND:
+// "local" (the default) writes under EXPORT_STORAGE_PATH, "s3" writes to an
+// S3-compatible bucket such as MinIO
+func NewBlobStore() (BlobStore, error) {
+	switch backend := os.Getenv("EXPORT_STORAGE_BACKEND"); backend {
+	case "", "local":
+		root := os.Getenv("EXPORT_STORAGE_PATH")
+		if root == "" {
+			root = "./exports"
+		}
+		return NewLocalStore(root)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        os.Getenv("EXPORT_S3_ENDPOINT"),
+			Region:          os.Getenv("EXPORT_S3_REGION"),
+			Bucket:          os.Getenv("EXPORT_S3_BUCKET"),
+			AccessKeyID:     os.Getenv("EXPORT_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("EXPORT_S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("EXPORT_S3_PATH_STYLE") != "false",
+		})
+	default:
+		return nil, fmt.Errorf("unknown EXPORT_STORAGE_BACKEND %q", backend)
+	}
+}
+
+// validKey rejects keys that could escape the store's namespace
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
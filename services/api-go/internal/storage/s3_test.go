@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestS3Store connects to the bucket configured by the EXPORT_S3_*
+// variables, such as a local MinIO, and skips the test when none is set
+func newTestS3Store(t *testing.T) *S3Store {
+	t.Helper()
+	endpoint := os.Getenv("EXPORT_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("EXPORT_S3_ENDPOINT is not set")
+	}
+	store, err := NewS3Store(S3Config{
+		Endpoint:        endpoint,
+		Region:          os.Getenv("EXPORT_S3_REGION"),
+		Bucket:          os.Getenv("EXPORT_S3_BUCKET"),
+		AccessKeyID:     os.Getenv("EXPORT_S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("EXPORT_S3_SECRET_ACCESS_KEY"),
+		PathStyle:       os.Getenv("EXPORT_S3_PATH_STYLE") != "false",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	return store
+}
+
+func TestS3StoreRoundTrip(t *testing.T) {
+	store := newTestS3Store(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	prefix := "storage-test/" + uuid.NewString() + "/"
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"single", 1 << 20},
+		{"multipart", s3PartSize + 1<<20}, // A full part and a short last one
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := prefix + tt.name + ".bin"
+			blob := make([]byte, tt.size)
+			for i := range blob {
+				blob[i] = byte(i*31 + i>>16)
+			}
+
+			if err := store.Put(ctx, key, bytes.NewReader(blob), int64(len(blob)), "application/octet-stream"); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			defer store.Delete(context.Background(), key)
+
+			reader, err := store.Open(ctx, key)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatalf("reading blob: %v", err)
+			}
+			if !bytes.Equal(got, blob) {
+				t.Fatalf("read %d bytes that differ from the %d written", len(got), len(blob))
+			}
+
+			if err := store.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := store.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Open after Delete: got %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestS3StoreDeleteMissing(t *testing.T) {
+	store := newTestS3Store(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := store.Delete(ctx, "storage-test/"+uuid.NewString()+"/missing.bin"); err != nil {
+		t.Fatalf("Delete of a missing blob: %v", err)
+	}
+}
@@ -0,0 +1,428 @@
+package subjects
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/google/uuid"
+)
+
+// accessExportTTL is how long a completed access export can be downloaded
+const accessExportTTL = 24 * time.Hour
+
+// AccessBundle is the machine-readable export of everything held about a subject
+type AccessBundle struct {
+	SubjectID         string                            `json:"subject_id"`
+	GeneratedAt       time.Time                         `json:"generated_at"`
+	Verifications     []models.PersonaVerification      `json:"verifications"`
+	ConsentTokens     []models.ConsentToken             `json:"consent_tokens"`
+	ReputationHistory []models.ReputationScore          `json:"reputation_history"`
+	ReputationSignals []models.ReputationSignal         `json:"reputation_signals"`
+	SignalAppeals     []models.SignalAppeal             `json:"signal_appeals"`
+	Links             []models.ConsensualLink           `json:"links"`
+	TrustBadges       []models.TrustBadge               `json:"trust_badges"`
+	TrustAnomalies    []models.TrustAnomaly             `json:"trust_anomalies"`
+	FederationLinks   []models.ReputationFederationLink `json:"federation_links"`
+	AuditEvents       []models.EventLog                 `json:"audit_events"`
+	WebhookPayloads   []WebhookPayloadRecord            `json:"webhook_payloads"`
+}
+
+// WebhookPayloadRecord is a webhook delivery that mentioned the subject
+type WebhookPayloadRecord struct {
+	DeliveryID  uuid.UUID       `json:"delivery_id"`
+	EventID     uuid.UUID       `json:"event_id"`
+	EndpointURL string          `json:"endpoint_url"`
+	Status      string          `json:"status"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// AccessManifest describes an access export so its contents can be verified
+type AccessManifest struct {
+	Type             string                     `json:"type"`
+	ExportID         uuid.UUID                  `json:"export_id"`
+	TenantID         uuid.UUID                  `json:"tenant_id"`
+	SubjectReference string                     `json:"subject_reference"` // SHA-256("{export_id}:{subject_id}")
+	GeneratedAt      time.Time                  `json:"generated_at"`
+	ExpiresAt        time.Time                  `json:"expires_at"`
+	Sections         map[string]ManifestSection `json:"sections"`
+	BundleSHA256     string                     `json:"bundle_sha256"`
+}
+
+// ManifestSection records the size and digest of one bundle section
+type ManifestSection struct {
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// SignedAccessManifest bundles the manifest with its detached signature
+type SignedAccessManifest struct {
+	Manifest  json.RawMessage   `json:"manifest"`
+	Signature signing.Signature `json:"signature"`
+}
+
+// AccessExportFile is the downloadable document
+type AccessExportFile struct {
+	Manifest SignedAccessManifest `json:"manifest"`
+	Data     json.RawMessage      `json:"data"`
+}
+
+// CreateAccessExport queues an asynchronous access export for a subject
+func (s *Service) CreateAccessExport(ctx context.Context, tenantID uuid.UUID, subjectID string) (*models.SubjectAccessExport, error) {
+	encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+
+	export := models.SubjectAccessExport{
+		TenantID:     tenantID,
+		SubjectID:    encryptedSubject,
+		SubjectIndex: s.crypto.BlindIndex(tenantID, subjectID),
+		Status:       "pending",
+		MaxAttempts:  subjectJobMaxAttempts,
+		RunAfter:     time.Now(),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to create access export: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "subject.access_export_requested",
+		SubjectID:    &subjectID,
+		ResourceType: stringPtr("subject_access_export"),
+		ResourceID:   &export.ID,
+		Metadata:     map[string]interface{}{},
+	})
+
+	s.wakeRunner()
+
+	return &export, nil
+}
+
+// GetAccessExport retrieves an access export and signs a fresh download link when ready
+func (s *Service) GetAccessExport(ctx context.Context, tenantID uuid.UUID, subjectID string, exportID uuid.UUID) (*models.SubjectAccessExport, error) {
+	var export models.SubjectAccessExport
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ? AND subject_index = ?", exportID, tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		First(&export).Error; err != nil {
+		return nil, err
+	}
+
+	if export.Status == "completed" && export.ExpiresAt != nil && export.ExpiresAt.After(time.Now()) {
+		url := s.urls.Sign(accessExportDownloadPath(export.ID), *export.ExpiresAt)
+		export.DownloadURL = &url
+	}
+	return &export, nil
+}
+
+// OpenAccessExport verifies a signed download link and returns the export file
+func (s *Service) OpenAccessExport(ctx context.Context, exportID uuid.UUID, expires, signature string) ([]byte, error) {
+	if err := s.urls.Verify(accessExportDownloadPath(exportID), expires, signature); err != nil {
+		return nil, err
+	}
+
+	var export models.SubjectAccessExport
+	if err := s.db.WithContext(ctx).Where("id = ? AND status = ?", exportID, "completed").First(&export).Error; err != nil {
+		return nil, fmt.Errorf("export not found")
+	}
+	if export.Bundle == nil || export.Manifest == nil || export.ExpiresAt == nil || export.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("link expired")
+	}
+
+	data, err := s.crypto.DecryptJSON(ctx, export.TenantID, *export.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SignedAccessManifest
+	if err := json.Unmarshal([]byte(*export.Manifest), &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return json.Marshal(AccessExportFile{Manifest: manifest, Data: json.RawMessage(data)})
+}
+
+// processAccessExport runs a claimed access export job
+func (s *Service) processAccessExport(ctx context.Context, exportID uuid.UUID) error {
+	var export models.SubjectAccessExport
+	if err := s.db.WithContext(ctx).Where("id = ?", exportID).First(&export).Error; err != nil {
+		return fmt.Errorf("failed to load access export: %w", err)
+	}
+
+	subjectID, err := s.crypto.Decrypt(ctx, export.TenantID, export.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	// 1. Collect everything held about the subject
+	bundle, err := s.collectAccessBundle(ctx, export.TenantID, export.SubjectIndex, subjectID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	// 2. Sign a manifest over the bundle and each of its sections
+	expiresAt := time.Now().Add(accessExportTTL)
+	manifest, recordCount, err := s.signAccessManifest(&export, subjectID, bundle, data, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// 3. Store the bundle encrypted until it expires
+	sealed, err := s.crypto.EncryptJSON(ctx, export.TenantID, string(data))
+	if err != nil {
+		return err
+	}
+
+	completed := s.leaseHeld(s.db.WithContext(ctx).Model(&models.SubjectAccessExport{}), exportID).Updates(map[string]interface{}{
+		"status":          "completed",
+		"bundle":          sealed,
+		"manifest":        manifest,
+		"record_count":    recordCount,
+		"file_size_bytes": int64(len(data)),
+		"error_message":   nil,
+		"locked_by":       nil,
+		"heartbeat_at":    nil,
+		"completed_at":    time.Now(),
+		"expires_at":      expiresAt,
+	})
+	if completed.Error != nil {
+		return fmt.Errorf("failed to complete access export: %w", completed.Error)
+	}
+	if completed.RowsAffected == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// collectAccessBundle gathers the subject's records from every module
+func (s *Service) collectAccessBundle(ctx context.Context, tenantID uuid.UUID, subjectIndex, subjectID string) (*AccessBundle, error) {
+	bundle := &AccessBundle{
+		SubjectID:   subjectID,
+		GeneratedAt: time.Now(),
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("created_at ASC").Find(&bundle.Verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to load verifications: %w", err)
+	}
+	for i := range bundle.Verifications {
+		if err := s.openVerification(ctx, &bundle.Verifications[i], subjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Consent tokens include their signed receipts
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND ? = ANY(parties)", tenantID, subjectID).
+		Order("created_at ASC").Find(&bundle.ConsentTokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to load consent tokens: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("calculated_at ASC").Find(&bundle.ReputationHistory).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputation history: %w", err)
+	}
+	for i := range bundle.ReputationHistory {
+		bundle.ReputationHistory[i].SubjectID = subjectID
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("occurred_at ASC").Find(&bundle.ReputationSignals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputation signals: %w", err)
+	}
+	for i := range bundle.ReputationSignals {
+		sig := &bundle.ReputationSignals[i]
+		sig.SubjectID = subjectID
+		source, err := s.crypto.DecryptPtr(ctx, tenantID, sig.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		sig.SourceID = source
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("created_at ASC").Find(&bundle.SignalAppeals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load appeals: %w", err)
+	}
+	for i := range bundle.SignalAppeals {
+		a := &bundle.SignalAppeals[i]
+		a.SubjectID = subjectID
+		reason, err := s.crypto.Decrypt(ctx, tenantID, a.Reason)
+		if err != nil {
+			return nil, err
+		}
+		a.Reason = reason
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND (initiator_index = ? OR recipient_index = ?)", tenantID, subjectIndex, subjectIndex).
+		Order("created_at ASC").Find(&bundle.Links).Error; err != nil {
+		return nil, fmt.Errorf("failed to load links: %w", err)
+	}
+	for i := range bundle.Links {
+		l := &bundle.Links[i]
+		// Only the subject's own side of the link is disclosed
+		l.InitiatorID, l.RecipientID = "", ""
+		if l.InitiatorIndex == subjectIndex {
+			l.InitiatorID = subjectID
+		}
+		if l.RecipientIndex == subjectIndex {
+			l.RecipientID = subjectID
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("awarded_at ASC").Find(&bundle.TrustBadges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load badges: %w", err)
+	}
+	for i := range bundle.TrustBadges {
+		bundle.TrustBadges[i].SubjectID = subjectID
+	}
+
+	// Anomalies the subject was flagged in, without the other members
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND id IN (?)", tenantID,
+		s.db.Model(&models.TrustAnomalyMember{}).Select("anomaly_id").Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex)).
+		Order("detected_at ASC").Find(&bundle.TrustAnomalies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load trust anomalies: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("created_at ASC").Find(&bundle.FederationLinks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load federation links: %w", err)
+	}
+	for i := range bundle.FederationLinks {
+		bundle.FederationLinks[i].SubjectID = subjectID
+	}
+
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Order("created_at ASC").Find(&bundle.AuditEvents).Error; err != nil {
+		return nil, fmt.Errorf("failed to load audit events: %w", err)
+	}
+	eventIDs := make([]uuid.UUID, 0, len(bundle.AuditEvents))
+	for i := range bundle.AuditEvents {
+		if err := s.audit.DecryptEvent(ctx, &bundle.AuditEvents[i]); err != nil {
+			return nil, err
+		}
+		eventIDs = append(eventIDs, bundle.AuditEvents[i].ID)
+	}
+
+	// Webhook payloads for the subject's events, plus any that quote the identifier
+	var deliveries []models.WebhookDelivery
+	if err := s.db.WithContext(ctx).Preload("WebhookEndpoint").
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.webhook_endpoint_id").
+		Where("webhook_endpoints.tenant_id = ?", tenantID).
+		Where("webhook_deliveries.event_id IN ? OR webhook_deliveries.request_payload::text LIKE ?", append(eventIDs, uuid.Nil), "%"+escapeLike(subjectID)+"%").
+		Order("webhook_deliveries.created_at ASC").
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook payloads: %w", err)
+	}
+	bundle.WebhookPayloads = make([]WebhookPayloadRecord, 0, len(deliveries))
+	for _, d := range deliveries {
+		bundle.WebhookPayloads = append(bundle.WebhookPayloads, WebhookPayloadRecord{
+			DeliveryID:  d.ID,
+			EventID:     d.EventID,
+			EndpointURL: d.WebhookEndpoint.URL,
+			Status:      d.Status,
+			Payload:     json.RawMessage(d.RequestPayload),
+			CreatedAt:   d.CreatedAt,
+		})
+	}
+
+	return bundle, nil
+}
+
+// signAccessManifest builds and signs the manifest for a bundle
+func (s *Service) signAccessManifest(export *models.SubjectAccessExport, subjectID string, bundle *AccessBundle, data []byte, expiresAt time.Time) (string, int, error) {
+	sections := map[string]ManifestSection{}
+	recordCount := 0
+	addSection := func(name string, records int, content interface{}) {
+		raw, _ := json.Marshal(content)
+		sections[name] = ManifestSection{Records: records, SHA256: sha256Hex(raw)}
+		recordCount += records
+	}
+	addSection("verifications", len(bundle.Verifications), bundle.Verifications)
+	addSection("consent_tokens", len(bundle.ConsentTokens), bundle.ConsentTokens)
+	addSection("reputation_history", len(bundle.ReputationHistory), bundle.ReputationHistory)
+	addSection("reputation_signals", len(bundle.ReputationSignals), bundle.ReputationSignals)
+	addSection("signal_appeals", len(bundle.SignalAppeals), bundle.SignalAppeals)
+	addSection("links", len(bundle.Links), bundle.Links)
+	addSection("trust_badges", len(bundle.TrustBadges), bundle.TrustBadges)
+	addSection("trust_anomalies", len(bundle.TrustAnomalies), bundle.TrustAnomalies)
+	addSection("federation_links", len(bundle.FederationLinks), bundle.FederationLinks)
+	addSection("audit_events", len(bundle.AuditEvents), bundle.AuditEvents)
+	addSection("webhook_payloads", len(bundle.WebhookPayloads), bundle.WebhookPayloads)
+
+	payload, signature, err := s.signer.SignJSON(AccessManifest{
+		Type:             "subject_access_export",
+		ExportID:         export.ID,
+		TenantID:         export.TenantID,
+		SubjectReference: sha256Hex([]byte(export.ID.String() + ":" + subjectID)),
+		GeneratedAt:      bundle.GeneratedAt,
+		ExpiresAt:        expiresAt,
+		Sections:         sections,
+		BundleSHA256:     sha256Hex(data),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to sign manifest: %w", err)
+	}
+
+	signed, err := json.Marshal(SignedAccessManifest{Manifest: payload, Signature: signature})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return string(signed), recordCount, nil
+}
+
+// Worker runs queued subject jobs, sweeps jobs abandoned by stopped runners,
+// and purges access export bundles once their download window has passed
+func (s *Service) Worker(ctx context.Context) {
+	go s.runJobs(ctx)
+
+	sweep := time.NewTicker(subjectJobLeaseTimeout)
+	defer sweep.Stop()
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sweep.C:
+			s.sweepStaleJobs(ctx)
+		case <-ticker.C:
+			if err := s.db.WithContext(ctx).Model(&models.SubjectAccessExport{}).
+				Where("expires_at < ? AND bundle IS NOT NULL", time.Now()).
+				Update("bundle", nil).Error; err != nil {
+				log.Printf("Error purging expired access exports: %v", err)
+			}
+		}
+	}
+}
+
+func accessExportDownloadPath(id uuid.UUID) string {
+	return "/downloads/subject-access-exports/" + id.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// escapeLike escapes LIKE wildcards in a literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,519 @@
+package subjects
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/pseudonym"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/reputation"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErasureSummary counts what an erasure removed or redacted
+type ErasureSummary struct {
+	VerificationsDeleted      int `json:"verifications_deleted"`
+	ReputationScoresDeleted   int `json:"reputation_scores_deleted"`
+	SignalsDeleted            int `json:"signals_deleted"`
+	SignalSourcesRedacted     int `json:"signal_sources_redacted"`
+	AppealsDeleted            int `json:"appeals_deleted"`
+	LinksDeleted              int `json:"links_deleted"`
+	BadgesDeleted             int `json:"badges_deleted"`
+	AnomalyMembershipsDeleted int `json:"anomaly_memberships_deleted"`
+	FederationLinksDeleted    int `json:"federation_links_deleted"`
+	ConsentTokensRedacted     int `json:"consent_tokens_redacted"`
+	EventsRedacted            int `json:"events_redacted"`
+	WebhookPayloadsRedacted   int `json:"webhook_payloads_redacted"`
+	CacheKeysPurged           int `json:"cache_keys_purged"`
+}
+
+// ErasureCertificate is the statement signed when an erasure completes
+type ErasureCertificate struct {
+	Type     string    `json:"type"`
+	JobID    uuid.UUID `json:"job_id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	// SubjectReference is SHA-256("{job_id}:{subject_id}") so the tenant can
+	// match the certificate to its request without us retaining the identifier.
+	SubjectReference string         `json:"subject_reference"`
+	Erased           ErasureSummary `json:"erased"`
+	// TombstoneDigest is SHA-256 over the sorted content digests of all tombstones
+	TombstoneDigest string    `json:"tombstone_digest"`
+	RequestedAt     time.Time `json:"requested_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
+
+// SignedErasureCertificate bundles the certificate with its detached signature
+type SignedErasureCertificate struct {
+	Certificate json.RawMessage   `json:"certificate"`
+	Signature   signing.Signature `json:"signature"`
+}
+
+// redactedMetadata replaces event metadata after erasure
+const redactedMetadata = `{"redacted": true}`
+
+// RequestErasure queues an asynchronous erasure of everything held about a subject
+func (s *Service) RequestErasure(ctx context.Context, tenantID uuid.UUID, subjectID string, requestedBy *string) (*models.SubjectErasureJob, error) {
+	subjectIndex := s.crypto.BlindIndex(tenantID, subjectID)
+
+	// Repeated requests for the same subject share the running job
+	var existing models.SubjectErasureJob
+	err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND subject_index = ? AND status IN ?", tenantID, subjectIndex, []string{"pending", "processing"}).
+		First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to check existing erasure jobs: %w", err)
+	}
+
+	encryptedSubject, err := s.crypto.Encrypt(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt subject: %w", err)
+	}
+
+	job := models.SubjectErasureJob{
+		TenantID:     tenantID,
+		SubjectID:    &encryptedSubject,
+		SubjectIndex: subjectIndex,
+		RequestedBy:  requestedBy,
+		Status:       "pending",
+		Summary:      "{}",
+		MaxAttempts:  subjectJobMaxAttempts,
+		RunAfter:     time.Now(),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create erasure job: %w", err)
+	}
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     tenantID,
+		EventType:    "subject.erasure_requested",
+		ActorID:      requestedBy,
+		ResourceType: stringPtr("subject_erasure"),
+		ResourceID:   &job.ID,
+		Metadata:     map[string]interface{}{},
+	})
+
+	s.wakeRunner()
+
+	return &job, nil
+}
+
+// GetErasureJob retrieves an erasure job
+func (s *Service) GetErasureJob(ctx context.Context, tenantID uuid.UUID, subjectID string, jobID uuid.UUID) (*models.SubjectErasureJob, error) {
+	var job models.SubjectErasureJob
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ? AND subject_index = ?", jobID, tenantID, s.crypto.BlindIndex(tenantID, subjectID)).
+		First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// processErasure runs a claimed erasure job. The records are erased and the
+// job completed in one transaction, so an interrupted attempt leaves nothing
+// half-erased and is simply run again.
+func (s *Service) processErasure(ctx context.Context, jobID uuid.UUID) error {
+	var job models.SubjectErasureJob
+	if err := s.db.WithContext(ctx).Where("id = ?", jobID).First(&job).Error; err != nil {
+		return fmt.Errorf("failed to load erasure job: %w", err)
+	}
+	if job.SubjectID == nil {
+		return fmt.Errorf("subject identifier missing")
+	}
+	subjectID, err := s.crypto.Decrypt(ctx, job.TenantID, *job.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	// Purge cached reputation and profiles before the rows go, and again
+	// after, so nothing read in between outlives the erasure
+	var summary ErasureSummary
+	summary.CacheKeysPurged = s.purgeSubjectCaches(ctx, &job, subjectID)
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		digests, err := s.eraseRecords(ctx, tx, &job, subjectID, &summary)
+		if err != nil {
+			return err
+		}
+
+		certificate, err := s.issueCertificate(&job, subjectID, summary, digests)
+		if err != nil {
+			return err
+		}
+		summaryJSON, err := json.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("failed to marshal erasure summary: %w", err)
+		}
+
+		completed := s.leaseHeld(tx.Model(&models.SubjectErasureJob{}), jobID).Updates(map[string]interface{}{
+			"status":        "completed",
+			"subject_id":    nil,
+			"summary":       string(summaryJSON),
+			"certificate":   certificate,
+			"error_message": nil,
+			"locked_by":     nil,
+			"heartbeat_at":  nil,
+			"completed_at":  time.Now(),
+		})
+		if completed.Error != nil {
+			return fmt.Errorf("failed to complete erasure job: %w", completed.Error)
+		}
+		if completed.RowsAffected == 0 {
+			return errLeaseLost
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.purgeSubjectCaches(ctx, &job, subjectID)
+
+	s.audit.LogEvent(ctx, audit.LogEventInput{
+		TenantID:     job.TenantID,
+		EventType:    "subject.erased",
+		ResourceType: stringPtr("subject_erasure"),
+		ResourceID:   &job.ID,
+		Metadata: map[string]interface{}{
+			"verifications_deleted":     summary.VerificationsDeleted,
+			"reputation_scores_deleted": summary.ReputationScoresDeleted,
+			"consent_tokens_redacted":   summary.ConsentTokensRedacted,
+			"events_redacted":           summary.EventsRedacted,
+		},
+	})
+	return nil
+}
+
+// purgeSubjectCaches drops the subject's cached reputation and profile,
+// returning how many keys were removed
+func (s *Service) purgeSubjectCaches(ctx context.Context, job *models.SubjectErasureJob, subjectID string) int {
+	purged, err := s.redisClient.Del(ctx, reputation.CacheKey(job.TenantID, job.SubjectIndex)).Result()
+	if err != nil {
+		log.Printf("Erasure job %s: failed to purge reputation cache: %v", job.ID, err)
+	}
+	profilePurged, err := s.redisClient.Del(ctx, profileCacheKey(job.TenantID, job.SubjectIndex)).Result()
+	if err != nil {
+		log.Printf("Erasure job %s: failed to purge profile cache: %v", job.ID, err)
+	}
+	return int(purged + profilePurged)
+}
+
+// eraseRecords removes or redacts the subject's rows and writes a tombstone for each
+func (s *Service) eraseRecords(ctx context.Context, tx *gorm.DB, job *models.SubjectErasureJob, subjectID string, summary *ErasureSummary) ([]string, error) {
+	var digests []string
+	tombstone := func(table string, recordID uuid.UUID, action, digest string) error {
+		digests = append(digests, digest)
+		return tx.Create(&models.ErasureTombstone{
+			TenantID:      job.TenantID,
+			ErasureJobID:  job.ID,
+			SourceTable:   table,
+			RecordID:      recordID,
+			Action:        action,
+			ContentDigest: digest,
+			ErasedAt:      time.Now(),
+		}).Error
+	}
+
+	// 1. Persona verifications are removed entirely
+	var verifications []models.PersonaVerification
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to load verifications: %w", err)
+	}
+	for _, v := range verifications {
+		if err := tombstone("persona_verifications", v.ID, "deleted", rowDigest(v)); err != nil {
+			return nil, err
+		}
+	}
+	if len(verifications) > 0 {
+		if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.PersonaVerification{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete verifications: %w", err)
+		}
+	}
+	summary.VerificationsDeleted = len(verifications)
+
+	// Pseudonym mappings for the subject and its verification proofs go with them
+	if err := tx.Where("tenant_id = ? AND namespace = ? AND external_index = ?", job.TenantID, pseudonym.NamespaceSubject, job.SubjectIndex).
+		Delete(&models.SubjectPseudonym{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete pseudonyms: %w", err)
+	}
+	var proofPseudonyms []string
+	for _, v := range verifications {
+		if v.ProofHash != nil && *v.ProofHash != "" {
+			proofPseudonyms = append(proofPseudonyms, *v.ProofHash)
+		}
+	}
+	if len(proofPseudonyms) > 0 {
+		if err := tx.Where("tenant_id = ? AND namespace <> ? AND pseudonym IN ?", job.TenantID, pseudonym.NamespaceSubject, proofPseudonyms).
+			Delete(&models.SubjectPseudonym{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete proof pseudonyms: %w", err)
+		}
+	}
+
+	// 2. Reputation score history is removed entirely
+	var scores []models.ReputationScore
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&scores).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputation scores: %w", err)
+	}
+	for _, sc := range scores {
+		if err := tombstone("reputation_scores", sc.ID, "deleted", rowDigest(sc)); err != nil {
+			return nil, err
+		}
+	}
+	if len(scores) > 0 {
+		if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.ReputationScore{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete reputation scores: %w", err)
+		}
+	}
+	summary.ReputationScoresDeleted = len(scores)
+
+	// Watches on the subject are tenant configuration, dropped without tombstones
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).
+		Delete(&models.ReputationWatch{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete reputation watches: %w", err)
+	}
+
+	// Appeals go with the signals they challenge
+	var appeals []models.SignalAppeal
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&appeals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load appeals: %w", err)
+	}
+	for _, a := range appeals {
+		if err := tombstone("signal_appeals", a.ID, "deleted", rowDigest(a)); err != nil {
+			return nil, err
+		}
+	}
+	if len(appeals) > 0 {
+		if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.SignalAppeal{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete appeals: %w", err)
+		}
+	}
+	summary.AppealsDeleted = len(appeals)
+
+	// Signals about the subject are removed; signals the subject attributed
+	// to others keep their weight but lose the source identifier
+	var signals []models.ReputationSignal
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&signals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputation signals: %w", err)
+	}
+	for _, sig := range signals {
+		if err := tombstone("reputation_signals", sig.ID, "deleted", rowDigest(sig)); err != nil {
+			return nil, err
+		}
+	}
+	if len(signals) > 0 {
+		if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.ReputationSignal{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete reputation signals: %w", err)
+		}
+	}
+	summary.SignalsDeleted = len(signals)
+
+	var sourced []models.ReputationSignal
+	if err := tx.Where("tenant_id = ? AND source_index = ?", job.TenantID, job.SubjectIndex).Find(&sourced).Error; err != nil {
+		return nil, fmt.Errorf("failed to load attributed signals: %w", err)
+	}
+	for _, sig := range sourced {
+		if err := tombstone("reputation_signals", sig.ID, "redacted", rowDigest(sig)); err != nil {
+			return nil, err
+		}
+	}
+	if len(sourced) > 0 {
+		if err := tx.Model(&models.ReputationSignal{}).Where("tenant_id = ? AND source_index = ?", job.TenantID, job.SubjectIndex).
+			Updates(map[string]interface{}{"source_id": nil, "source_index": nil}).Error; err != nil {
+			return nil, fmt.Errorf("failed to redact signal sources: %w", err)
+		}
+	}
+	summary.SignalSourcesRedacted = len(sourced)
+
+	// Links naming the subject are removed with the badges it holds. Badges the
+	// counterpart holds stay, but lose the reference to the subject.
+	var subjectLinks []models.ConsensualLink
+	if err := tx.Where("tenant_id = ? AND (initiator_index = ? OR recipient_index = ?)", job.TenantID, job.SubjectIndex, job.SubjectIndex).
+		Find(&subjectLinks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load links: %w", err)
+	}
+	for _, l := range subjectLinks {
+		if err := tombstone("consensual_links", l.ID, "deleted", rowDigest(l)); err != nil {
+			return nil, err
+		}
+	}
+	var badges []models.TrustBadge
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&badges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load badges: %w", err)
+	}
+	for _, b := range badges {
+		if err := tombstone("trust_badges", b.ID, "deleted", rowDigest(b)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.TrustBadge{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete badges: %w", err)
+	}
+	if err := tx.Model(&models.TrustBadge{}).Where("tenant_id = ? AND linked_subject_index = ?", job.TenantID, job.SubjectIndex).
+		Update("linked_subject_index", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to redact badges: %w", err)
+	}
+	if err := tx.Where("tenant_id = ? AND (initiator_index = ? OR recipient_index = ?)", job.TenantID, job.SubjectIndex, job.SubjectIndex).
+		Delete(&models.ConsensualLink{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete links: %w", err)
+	}
+	summary.LinksDeleted = len(subjectLinks)
+	summary.BadgesDeleted = len(badges)
+
+	// Anomaly memberships are removed; the anomaly stays open for the other members
+	var memberships []models.TrustAnomalyMember
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to load anomaly memberships: %w", err)
+	}
+	for _, m := range memberships {
+		if err := tombstone("trust_anomaly_members", m.ID, "deleted", rowDigest(m)); err != nil {
+			return nil, err
+		}
+	}
+	if len(memberships) > 0 {
+		if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Delete(&models.TrustAnomalyMember{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete anomaly memberships: %w", err)
+		}
+	}
+	summary.AnomalyMembershipsDeleted = len(memberships)
+
+	// Federation links go both ways: links reading this subject's reputation
+	// from another tenant, and other tenants' links reading it from here
+	federation := tx.Where("(tenant_id = ? AND subject_index = ?) OR (source_tenant_id = ? AND source_subject_index = ?)",
+		job.TenantID, job.SubjectIndex, job.TenantID, job.SubjectIndex).Session(&gorm.Session{})
+	var federationLinks []models.ReputationFederationLink
+	if err := federation.Find(&federationLinks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load federation links: %w", err)
+	}
+	for _, l := range federationLinks {
+		if err := tombstone("reputation_federation_links", l.ID, "deleted", rowDigest(l)); err != nil {
+			return nil, err
+		}
+	}
+	if len(federationLinks) > 0 {
+		if err := federation.Delete(&models.ReputationFederationLink{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete federation links: %w", err)
+		}
+	}
+	summary.FederationLinksDeleted = len(federationLinks)
+
+	// 3. Consent tokens keep their other parties; the subject's entry is replaced.
+	// The receipt embeds the party list, so only its signature is retained.
+	erasedParty := "erased:" + job.ID.String()
+	var tokens []models.ConsentToken
+	if err := tx.Where("tenant_id = ? AND ? = ANY(parties)", job.TenantID, subjectID).Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to load consent tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if err := tombstone("consent_tokens", t.ID, "redacted", rowDigest(t)); err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&models.ConsentToken{}).Where("id = ?", t.ID).Updates(map[string]interface{}{
+			"parties":           gorm.Expr("array_replace(parties, ?, ?)", subjectID, erasedParty),
+			"receipt_signature": redactReceipt(t.ReceiptSignature),
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to redact consent token %s: %w", t.ID, err)
+		}
+	}
+	summary.ConsentTokensRedacted = len(tokens)
+
+	// 4. Audit events keep their type, resource and timestamp so the trail stays
+	// intact; personal fields are cleared and their digest kept as a tombstone.
+	var events []models.EventLog
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	eventIDs := make([]uuid.UUID, 0, len(events))
+	for _, e := range events {
+		if err := s.audit.DecryptEvent(ctx, &e); err != nil {
+			return nil, err
+		}
+		if err := tombstone("event_log", e.ID, "redacted", audit.PersonalDataDigest(e)); err != nil {
+			return nil, err
+		}
+		eventIDs = append(eventIDs, e.ID)
+	}
+	if len(eventIDs) > 0 {
+		if err := tx.Model(&models.EventLog{}).Where("id IN ?", eventIDs).Updates(map[string]interface{}{
+			"subject_id":     nil,
+			"subject_index":  nil,
+			"metadata":       redactedMetadata,
+			"metadata_index": nil,
+			"ip_address":     nil,
+			"user_agent":     nil,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to redact events: %w", err)
+		}
+
+		// Webhook payloads carry copies of the event metadata
+		result := tx.Model(&models.WebhookDelivery{}).Where("event_id IN ?", eventIDs).
+			Update("request_payload", redactedMetadata)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to redact webhook payloads: %w", result.Error)
+		}
+		summary.WebhookPayloadsRedacted = int(result.RowsAffected)
+	}
+	summary.EventsRedacted = len(events)
+
+	// 5. Access export bundles are copies of the above and are dropped outright
+	if err := tx.Where("tenant_id = ? AND subject_index = ?", job.TenantID, job.SubjectIndex).
+		Delete(&models.SubjectAccessExport{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete access exports: %w", err)
+	}
+
+	return digests, nil
+}
+
+// issueCertificate signs the erasure certificate and returns it as JSON
+func (s *Service) issueCertificate(job *models.SubjectErasureJob, subjectID string, summary ErasureSummary, digests []string) (string, error) {
+	sort.Strings(digests)
+	tombstoneHash := sha256.New()
+	for _, d := range digests {
+		tombstoneHash.Write([]byte(d))
+	}
+	subjectRef := sha256.Sum256([]byte(job.ID.String() + ":" + subjectID))
+
+	payload, signature, err := s.signer.SignJSON(ErasureCertificate{
+		Type:             "subject_erasure",
+		JobID:            job.ID,
+		TenantID:         job.TenantID,
+		SubjectReference: hex.EncodeToString(subjectRef[:]),
+		Erased:           summary,
+		TombstoneDigest:  hex.EncodeToString(tombstoneHash.Sum(nil)),
+		RequestedAt:      job.CreatedAt,
+		CompletedAt:      time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign erasure certificate: %w", err)
+	}
+
+	signed, err := json.Marshal(SignedErasureCertificate{Certificate: payload, Signature: signature})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal erasure certificate: %w", err)
+	}
+	return string(signed), nil
+}
+
+// rowDigest hashes the stored form of a row before it is removed or redacted
+func rowDigest(row interface{}) string {
+	content, _ := json.Marshal(row)
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// redactReceipt drops the party-bearing payload of a "v1.{payload}.{signature}" receipt
+func redactReceipt(receipt string) string {
+	signature := receipt[strings.LastIndex(receipt, ".")+1:]
+	return "v1.erased." + signature
+}
@@ -0,0 +1,193 @@
+package subjects
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler manages data subject HTTP endpoints
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new subjects handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetProfile handles GET /v1/subjects/:id
+func (h *Handler) GetProfile(c *gin.Context) {
+	tenantID, _ := middleware.GetTenantID(c)
+
+	profile, err := h.service.GetProfile(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "profile_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListVerifications handles GET /v1/subjects/:id/verifications
+func (h *Handler) ListVerifications(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	verifications, total, err := h.service.ListVerifications(c.Request.Context(), tenantID, c.Param("id"), ListVerificationsInput{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "list_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"verifications": verifications,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// DeleteSubject handles DELETE /v1/subjects/:id
+func (h *Handler) DeleteSubject(c *gin.Context) {
+	subjectID := c.Param("id")
+	if subjectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Subject ID is required",
+		})
+		return
+	}
+
+	var requestedBy *string
+	if actor := c.Query("requested_by"); actor != "" {
+		requestedBy = &actor
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	job, err := h.service.RequestErasure(c.Request.Context(), tenantID, subjectID, requestedBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "erasure_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetErasure handles GET /v1/subjects/:id/erasures/:job_id
+func (h *Handler) GetErasure(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Erasure job ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	job, err := h.service.GetErasureJob(c.Request.Context(), tenantID, c.Param("id"), jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Erasure job not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CreateAccessExport handles POST /v1/subjects/:id/access-exports
+func (h *Handler) CreateAccessExport(c *gin.Context) {
+	subjectID := c.Param("id")
+	if subjectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Subject ID is required",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	export, err := h.service.CreateAccessExport(c.Request.Context(), tenantID, subjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "export_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// GetAccessExport handles GET /v1/subjects/:id/access-exports/:export_id
+func (h *Handler) GetAccessExport(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("export_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Export ID must be a valid UUID",
+		})
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantID(c)
+
+	export, err := h.service.GetAccessExport(c.Request.Context(), tenantID, c.Param("id"), exportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Access export not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// DownloadAccessExport handles GET /downloads/subject-access-exports/:id
+// Authorised by the signed link rather than an API key.
+func (h *Handler) DownloadAccessExport(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_id",
+			"message": "Export ID must be a valid UUID",
+		})
+		return
+	}
+
+	file, err := h.service.OpenAccessExport(c.Request.Context(), exportID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "invalid_link",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\"subject-access-export-"+exportID.String()+".json\"")
+	c.Data(http.StatusOK, "application/json", file)
+}
@@ -0,0 +1,271 @@
+package subjects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// subjectJobMaxAttempts is how many times a job runs before it is failed
+	subjectJobMaxAttempts = 5
+
+	// subjectJobPollInterval is how often the idle runner looks for queued jobs
+	subjectJobPollInterval = 5 * time.Second
+
+	// subjectJobHeartbeatInterval is how often a running job renews its lease
+	subjectJobHeartbeatInterval = 10 * time.Second
+
+	// subjectJobLeaseTimeout is how long a running job may go without a
+	// heartbeat before another runner reclaims it
+	subjectJobLeaseTimeout = 2 * time.Minute
+
+	// subjectJobRetryBackoff is the delay before the first retry; it doubles
+	// with each attempt up to subjectJobMaxRetryBackoff
+	subjectJobRetryBackoff    = 30 * time.Second
+	subjectJobMaxRetryBackoff = 30 * time.Minute
+)
+
+// errLeaseLost is returned by a job that finished after its lease was lost
+var errLeaseLost = errors.New("job lease lost")
+
+// subjectJobQueue is a job table drained by the runner. Jobs run on any
+// replica: the table is the queue, leased with FOR UPDATE SKIP LOCKED.
+type subjectJobQueue struct {
+	name  string // For logs
+	table string
+	// run processes a claimed job. It must record completion only while
+	// this runner holds the lease (see leaseHeld), returning errLeaseLost
+	// otherwise.
+	run func(ctx context.Context, jobID uuid.UUID) error
+}
+
+// subjectJob is the queue state every job table shares
+type subjectJob struct {
+	ID          uuid.UUID
+	Attempts    int
+	MaxAttempts int
+}
+
+// jobQueues lists the queues the runner drains, in priority order
+func (s *Service) jobQueues() []subjectJobQueue {
+	return []subjectJobQueue{
+		{name: "erasure", table: "subject_erasure_jobs", run: s.processErasure},
+		{name: "access export", table: "subject_access_exports", run: s.processAccessExport},
+	}
+}
+
+// wakeRunner lets the idle local runner pick up a new job without waiting
+// for its next poll
+func (s *Service) wakeRunner() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runJobs claims and runs queued jobs until ctx ends
+func (s *Service) runJobs(ctx context.Context) {
+	ticker := time.NewTicker(subjectJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every queue before waiting again
+		for _, queue := range s.jobQueues() {
+			for ctx.Err() == nil {
+				job, err := s.claimJob(ctx, queue)
+				if err != nil {
+					log.Printf("Error claiming %s job: %v", queue.name, err)
+					break
+				}
+				if job == nil {
+					break
+				}
+				s.processJob(ctx, queue, job)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// claimJob leases the next due job: a pending job whose retry delay has
+// passed, or a running job whose runner stopped sending heartbeats, such as
+// one interrupted by a restart. Returns nil when no job is due.
+func (s *Service) claimJob(ctx context.Context, queue subjectJobQueue) (*subjectJob, error) {
+	var job subjectJob
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Table(queue.table).Select("id", "attempts", "max_attempts").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_after <= ?) OR (status = ? AND heartbeat_at < ?)",
+				"pending", now, "processing", now.Add(-subjectJobLeaseTimeout)).
+			Order("run_after ASC").Take(&job).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table(queue.table).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":       "processing",
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_by":    s.workerID,
+			"heartbeat_at": now,
+			"started_at":   gorm.Expr("COALESCE(started_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+		job.Attempts++
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// processJob runs a claimed job while renewing its lease. The job stops
+// when its lease is lost to another runner.
+func (s *Service) processJob(ctx context.Context, queue subjectJobQueue, job *subjectJob) {
+	if job.Attempts > job.MaxAttempts {
+		// Reclaimed after its last attempt's runner died
+		s.retryOrFail(queue, job, fmt.Errorf("job runner stopped responding"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	heartbeats := make(chan struct{})
+	go func() {
+		defer close(heartbeats)
+		s.heartbeat(ctx, queue, job, cancel)
+	}()
+
+	err := queue.run(ctx, job.ID)
+	cancel()
+	<-heartbeats
+
+	if errors.Is(err, errLeaseLost) {
+		log.Printf("%s job %s lost its lease; another runner owns it", queue.name, job.ID)
+		return
+	}
+	if err != nil {
+		s.retryOrFail(queue, job, err)
+	}
+}
+
+// heartbeat renews the job's lease until ctx ends, cancelling the job once
+// the lease is lost
+func (s *Service) heartbeat(ctx context.Context, queue subjectJobQueue, job *subjectJob, cancel context.CancelFunc) {
+	ticker := time.NewTicker(subjectJobHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed := s.leaseHeld(s.db.WithContext(ctx).Table(queue.table), job.ID).
+				Update("heartbeat_at", time.Now())
+			if renewed.Error != nil {
+				log.Printf("Error renewing %s job %s: %v", queue.name, job.ID, renewed.Error)
+				continue
+			}
+			if renewed.RowsAffected == 0 {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// retryOrFail requeues a failed attempt with exponential backoff, or fails
+// the job once it has used all its attempts
+func (s *Service) retryOrFail(queue subjectJobQueue, job *subjectJob, cause error) {
+	updates := map[string]interface{}{
+		"error_message": cause.Error(),
+		"locked_by":     nil,
+		"heartbeat_at":  nil,
+	}
+	if job.Attempts < job.MaxAttempts {
+		updates["status"] = "pending"
+		updates["run_after"] = time.Now().Add(subjectJobRetryDelay(job.Attempts))
+	} else {
+		updates["status"] = "failed"
+		updates["completed_at"] = time.Now()
+	}
+
+	result := s.leaseHeld(s.db.Table(queue.table), job.ID).Updates(updates)
+	if result.Error != nil {
+		log.Printf("Error updating %s job %s: %v", queue.name, job.ID, result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("%s job %s attempt %d failed: %v", queue.name, job.ID, job.Attempts, cause)
+	}
+}
+
+// sweepStaleJobs releases jobs whose runner stopped sending heartbeats, so
+// they read as pending rather than processing until a runner claims them
+// again. Those that used all their attempts are failed.
+func (s *Service) sweepStaleJobs(ctx context.Context) {
+	stale := time.Now().Add(-subjectJobLeaseTimeout)
+	for _, queue := range s.jobQueues() {
+		failed := s.db.WithContext(ctx).Table(queue.table).
+			Where("status = ? AND heartbeat_at < ? AND attempts >= max_attempts", "processing", stale).
+			Updates(map[string]interface{}{
+				"status":        "failed",
+				"error_message": "job runner stopped responding",
+				"locked_by":     nil,
+				"heartbeat_at":  nil,
+				"completed_at":  time.Now(),
+			})
+		if failed.Error != nil {
+			log.Printf("Error failing stale %s jobs: %v", queue.name, failed.Error)
+		}
+
+		requeued := s.db.WithContext(ctx).Table(queue.table).
+			Where("status = ? AND heartbeat_at < ?", "processing", stale).
+			Updates(map[string]interface{}{
+				"status":       "pending",
+				"locked_by":    nil,
+				"heartbeat_at": nil,
+				"run_after":    time.Now(),
+			})
+		if requeued.Error != nil {
+			log.Printf("Error requeueing stale %s jobs: %v", queue.name, requeued.Error)
+			continue
+		}
+		if failed.RowsAffected+requeued.RowsAffected > 0 {
+			log.Printf("Swept stale %s jobs: %d requeued, %d failed", queue.name, requeued.RowsAffected, failed.RowsAffected)
+		}
+	}
+}
+
+// leaseHeld scopes a query to the job while this runner holds its lease
+func (s *Service) leaseHeld(db *gorm.DB, jobID uuid.UUID) *gorm.DB {
+	return db.Where("id = ? AND status = ? AND locked_by = ?", jobID, "processing", s.workerID)
+}
+
+// subjectJobRetryDelay is the backoff after a failed attempt
+func subjectJobRetryDelay(attempts int) time.Duration {
+	delay := subjectJobRetryBackoff
+	for i := 1; i < attempts && delay < subjectJobMaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > subjectJobMaxRetryBackoff {
+		delay = subjectJobMaxRetryBackoff
+	}
+	return delay
+}
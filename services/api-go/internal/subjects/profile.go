@@ -0,0 +1,234 @@
+package subjects
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// profileCacheTTL bounds how stale a cached profile can get if an invalidation is missed
+const profileCacheTTL = 5 * time.Minute
+
+// Profile is the aggregate trust view of a subject
+type Profile struct {
+	SubjectID      string             `json:"subject_id"`
+	Verifications  []ProviderState    `json:"verifications"` // Latest verification per provider
+	Attributes     []string           `json:"attributes"`
+	Reputation     *ProfileReputation `json:"reputation"`
+	ActiveConsents int64              `json:"active_consents"`
+	OpenDisputes   int64              `json:"open_disputes"` // Open appeals of reputation signals
+	LastActivityAt *time.Time         `json:"last_activity_at"`
+	GeneratedAt    time.Time          `json:"generated_at"`
+	Cached         bool               `json:"cached"`
+}
+
+// ProviderState is the current verification state with one provider
+type ProviderState struct {
+	Provider        string     `json:"provider"`
+	Status          string     `json:"status"` // pending, verified, failed, expired
+	VerificationID  uuid.UUID  `json:"verification_id"`
+	ConfidenceScore *float64   `json:"confidence_score,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+// ProfileReputation summarises the subject's reputation
+type ProfileReputation struct {
+	Score float64 `json:"score"`
+	Level string  `json:"level"`
+}
+
+// ListVerificationsInput represents pagination for a subject's verifications
+type ListVerificationsInput struct {
+	Limit  int
+	Offset int
+}
+
+// GetProfile assembles the subject's profile, served from cache when possible
+func (s *Service) GetProfile(ctx context.Context, tenantID uuid.UUID, subjectID string) (*Profile, error) {
+	subjectIndex := s.crypto.BlindIndex(tenantID, subjectID)
+	cacheKey := profileCacheKey(tenantID, subjectIndex)
+
+	// 1. Check Cache
+	if val, err := s.redisClient.Get(ctx, cacheKey).Result(); err == nil {
+		var profile Profile
+		if err := json.Unmarshal([]byte(val), &profile); err == nil {
+			profile.Cached = true
+			return &profile, nil
+		}
+	}
+
+	profile := Profile{
+		SubjectID:     subjectID,
+		Verifications: []ProviderState{},
+		Attributes:    []string{},
+		GeneratedAt:   time.Now(),
+	}
+
+	// 2. Latest verification per provider
+	var latest []models.PersonaVerification
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (provider) *
+		FROM persona_verifications
+		WHERE tenant_id = ? AND subject_index = ?
+		ORDER BY provider, created_at DESC`, tenantID, subjectIndex).
+		Scan(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to load verifications: %w", err)
+	}
+	attributes := map[string]bool{}
+	for i := range latest {
+		v := &latest[i]
+		if err := s.openVerification(ctx, v, subjectID); err != nil {
+			return nil, err
+		}
+		state := ProviderState{
+			Provider:        v.Provider,
+			Status:          v.Status,
+			VerificationID:  v.ID,
+			ConfidenceScore: v.ConfidenceScore,
+			VerifiedAt:      v.VerifiedAt,
+			ExpiresAt:       v.ExpiresAt,
+		}
+		if state.Status == "verified" && v.ExpiresAt != nil && v.ExpiresAt.Before(profile.GeneratedAt) {
+			state.Status = "expired"
+		}
+		profile.Verifications = append(profile.Verifications, state)
+
+		if state.Status == "verified" {
+			for _, attr := range verificationAttributes(v) {
+				attributes[attr] = true
+			}
+		}
+	}
+	for attr := range attributes {
+		profile.Attributes = append(profile.Attributes, attr)
+	}
+	sort.Strings(profile.Attributes)
+
+	// 3. Reputation (cached separately by the reputation service)
+	rep, err := s.reputation.GetReputation(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reputation: %w", err)
+	}
+	profile.Reputation = &ProfileReputation{Score: rep.Score, Level: rep.Level}
+
+	// 4. Active consents
+	if err := s.db.WithContext(ctx).Model(&models.ConsentToken{}).
+		Where("tenant_id = ? AND ? = ANY(parties) AND status = ? AND expires_at > ?", tenantID, subjectID, "active", profile.GeneratedAt).
+		Count(&profile.ActiveConsents).Error; err != nil {
+		return nil, fmt.Errorf("failed to count consents: %w", err)
+	}
+
+	// 5. Open disputes
+	openDisputes, err := s.reputation.CountOpenAppeals(ctx, tenantID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open appeals: %w", err)
+	}
+	profile.OpenDisputes = openDisputes
+
+	// 6. Last activity
+	var lastActivity struct{ At *time.Time }
+	if err := s.db.WithContext(ctx).Model(&models.EventLog{}).
+		Select("MAX(created_at) AS at").
+		Where("tenant_id = ? AND subject_index = ?", tenantID, subjectIndex).
+		Scan(&lastActivity).Error; err != nil {
+		return nil, fmt.Errorf("failed to load last activity: %w", err)
+	}
+	profile.LastActivityAt = lastActivity.At
+
+	// 7. Cache Result
+	jsonBytes, _ := json.Marshal(profile)
+	s.redisClient.Set(ctx, cacheKey, jsonBytes, profileCacheTTL)
+
+	return &profile, nil
+}
+
+// ListVerifications returns the subject's verifications, newest first
+func (s *Service) ListVerifications(ctx context.Context, tenantID uuid.UUID, subjectID string, input ListVerificationsInput) ([]models.PersonaVerification, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.PersonaVerification{}).
+		Where("tenant_id = ? AND subject_index = ?", tenantID, s.crypto.BlindIndex(tenantID, subjectID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count verifications: %w", err)
+	}
+
+	if input.Limit <= 0 || input.Limit > 100 {
+		input.Limit = 100
+	}
+
+	var verifications []models.PersonaVerification
+	if err := query.Order("created_at DESC").Limit(input.Limit).Offset(input.Offset).Find(&verifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list verifications: %w", err)
+	}
+	for i := range verifications {
+		if err := s.openVerification(ctx, &verifications[i], subjectID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return verifications, total, nil
+}
+
+// InvalidateProfile drops the cached profile of a subject
+func (s *Service) InvalidateProfile(ctx context.Context, tenantID uuid.UUID, subjectID string) {
+	if err := s.redisClient.Del(ctx, profileCacheKey(tenantID, s.crypto.BlindIndex(tenantID, subjectID))).Err(); err != nil {
+		log.Printf("Failed to invalidate subject profile: %v", err)
+	}
+}
+
+// HandleEvent invalidates cached profiles touched by a domain event
+func (s *Service) HandleEvent(ctx context.Context, event events.Event) {
+	if event.SubjectID != nil {
+		s.InvalidateProfile(ctx, event.TenantID, *event.SubjectID)
+	}
+
+	// Consent events name the token, not the subject; invalidate every party
+	if events.Match("consent.*", event.Type) && event.ResourceID != nil {
+		var parties []string
+		if err := s.db.WithContext(ctx).Raw("SELECT unnest(parties) FROM consent_tokens WHERE id = ? AND tenant_id = ?", *event.ResourceID, event.TenantID).
+			Scan(&parties).Error; err != nil {
+			log.Printf("Failed to load consent parties for invalidation: %v", err)
+			return
+		}
+		for _, party := range parties {
+			s.InvalidateProfile(ctx, event.TenantID, party)
+		}
+	}
+}
+
+// openVerification decrypts a stored verification in place
+func (s *Service) openVerification(ctx context.Context, v *models.PersonaVerification, subjectID string) error {
+	data, err := s.crypto.DecryptJSON(ctx, v.TenantID, v.VerificationData)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt verification %s: %w", v.ID, err)
+	}
+	v.SubjectID = subjectID
+	v.VerificationData = data
+	return nil
+}
+
+// verificationAttributes lists the attributes a verified verification grants:
+// "verified:<provider>" plus any "attributes" reported by the provider
+func verificationAttributes(v *models.PersonaVerification) []string {
+	attrs := []string{"verified:" + v.Provider}
+
+	var data struct {
+		Attributes []string `json:"attributes"`
+	}
+	if err := json.Unmarshal([]byte(v.VerificationData), &data); err == nil {
+		attrs = append(attrs, data.Attributes...)
+	}
+	return attrs
+}
+
+func profileCacheKey(tenantID uuid.UUID, subjectIndex string) string {
+	return fmt.Sprintf("subject_profile:%s:%s", tenantID, subjectIndex)
+}
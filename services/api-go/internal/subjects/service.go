@@ -0,0 +1,46 @@
+package subjects
+
+import (
+	"os"
+
+	"github.com/dennislee928/mighty-eagle/api-go/internal/audit"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/reputation"
+	"github.com/dennislee928/mighty-eagle/api-go/internal/signing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Service manages data subject rights across all modules
+type Service struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	audit       *audit.Logger
+	crypto      *encryption.Service
+	signer      *signing.Signer
+	urls        *signing.URLSigner
+	reputation  *reputation.Service
+	workerID    string        // Identifies this replica's job leases
+	wake        chan struct{} // Signals a newly queued job
+}
+
+// NewService creates a new subjects service
+func NewService(db *gorm.DB, redisClient *redis.Client, audit *audit.Logger, crypto *encryption.Service, signer *signing.Signer, urls *signing.URLSigner, reputation *reputation.Service) *Service {
+	hostname, _ := os.Hostname()
+	return &Service{
+		db:          db,
+		redisClient: redisClient,
+		audit:       audit,
+		crypto:      crypto,
+		signer:      signer,
+		urls:        urls,
+		reputation:  reputation,
+		workerID:    hostname + "/" + uuid.NewString()[:8],
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -7,10 +7,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/dennislee928/mighty-eagle/api-go/internal/encryption"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -18,12 +20,13 @@ import (
 
 // Service manages webhooks
 type Service struct {
-	db *gorm.DB
+	db     *gorm.DB
+	crypto *encryption.Service
 }
 
 // NewService creates a new webhook service
-func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+func NewService(db *gorm.DB, crypto *encryption.Service) *Service {
+	return &Service{db: db, crypto: crypto}
 }
 
 // DeliveryPayload represents the JSON payload sent to webhooks
@@ -107,15 +110,20 @@ func (s *Service) Deliver(ctx context.Context, deliveryID uuid.UUID) error {
 		return err
 	}
 	
+	body, err := s.requestBody(ctx, delivery)
+	if err != nil {
+		return s.recordFailure(delivery, err.Error(), 0, nil)
+	}
+
 	// Prepare request
-	req, err := http.NewRequestWithContext(ctx, "POST", delivery.WebhookEndpoint.URL, bytes.NewBuffer([]byte(delivery.RequestPayload)))
+	req, err := http.NewRequestWithContext(ctx, "POST", delivery.WebhookEndpoint.URL, bytes.NewBuffer(body))
 	if err != nil {
 		return s.recordFailure(delivery, err.Error(), 0, nil)
 	}
 	
 	// Add headers
 	timestamp := time.Now().UTC().Format(time.RFC3339)
-	signature := SignPayload([]byte(delivery.RequestPayload), delivery.WebhookEndpoint.Secret)
+	signature := SignPayload(body, delivery.WebhookEndpoint.Secret)
 	
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "MightyEagle-Webhook/1.0")
@@ -140,6 +148,38 @@ func (s *Service) Deliver(ctx context.Context, deliveryID uuid.UUID) error {
 	return s.recordFailure(delivery, fmt.Sprintf("HTTP %d", resp.StatusCode), resp.StatusCode, nil)
 }
 
+// requestBody is the stored payload with the event's subject identifier
+// added. The identifier is read from the event log, where it is encrypted,
+// rather than stored with the delivery; once the subject is erased it is
+// no longer sent.
+func (s *Service) requestBody(ctx context.Context, delivery models.WebhookDelivery) ([]byte, error) {
+	var event models.EventLog
+	err := s.db.WithContext(ctx).Select("tenant_id", "subject_id").Where("id = ?", delivery.EventID).First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []byte(delivery.RequestPayload), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event: %w", err)
+	}
+	if event.SubjectID == nil {
+		return []byte(delivery.RequestPayload), nil
+	}
+
+	subjectID, err := s.crypto.Decrypt(ctx, event.TenantID, *event.SubjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt event subject: %w", err)
+	}
+	// Raw values keep the stored data byte for byte
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(delivery.RequestPayload), &payload); err != nil {
+		return nil, fmt.Errorf("invalid stored payload: %w", err)
+	}
+	if payload["subject_id"], err = json.Marshal(subjectID); err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
 func (s *Service) recordSuccess(d models.WebhookDelivery, statusCode int) error {
 	now := time.Now()
 	return s.db.Model(&d).Updates(map[string]interface{}{
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/dennislee928/mighty-eagle/api-go/internal/events"
 	"github.com/dennislee928/mighty-eagle/api-go/internal/models"
 )
 
@@ -22,7 +23,8 @@ func (s *Service) DispatchEvent(ctx context.Context, eventLog models.EventLog) e
 		return nil
 	}
 
-	// Create payload
+	// Create payload. The subject identifier is added when the delivery is
+	// sent (see requestBody) so it is never stored in plaintext.
 	payload := map[string]interface{}{
 		"id":         eventLog.ID,
 		"event":      eventLog.EventType,
@@ -30,7 +32,7 @@ func (s *Service) DispatchEvent(ctx context.Context, eventLog models.EventLog) e
 		"data":       json.RawMessage(eventLog.Metadata),
 		"resource":   eventLog.ResourceID,
 	}
-	
+
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -57,6 +59,32 @@ func (s *Service) DispatchEvent(ctx context.Context, eventLog models.EventLog) e
 	return nil
 }
 
+// HandleEvent queues deliveries for a recorded domain event.
+// Dispatch runs in the background so the publisher is not held up.
+func (s *Service) HandleEvent(ctx context.Context, event events.Event) {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		log.Printf("Failed to marshal webhook data for %s: %v", event.ID, err)
+		return
+	}
+
+	eventLog := models.EventLog{
+		ID:           event.ID,
+		TenantID:     event.TenantID,
+		EventType:    event.Type,
+		SubjectID:    event.SubjectID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Metadata:     string(metadata),
+		CreatedAt:    event.OccurredAt,
+	}
+	go func() {
+		if err := s.DispatchEvent(context.Background(), eventLog); err != nil {
+			log.Printf("Failed to dispatch webhook for %s: %v", event.ID, err)
+		}
+	}()
+}
+
 // Worker processes pending webhook deliveries
 func (s *Service) Worker(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
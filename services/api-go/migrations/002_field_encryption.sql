@@ -0,0 +1,69 @@
+-- Mighty Eagle Trust Layer - Field-level Encryption
+-- Envelope encryption of verification payloads and PII
+
+-- ============================================================================
+-- TENANT DATA KEYS
+-- ============================================================================
+
+-- Per-tenant data encryption keys, wrapped by a master key held in the keystore
+CREATE TABLE tenant_data_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    master_key_id VARCHAR(100) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'retired')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(tenant_id, version)
+);
+
+CREATE INDEX idx_tenant_data_keys_tenant ON tenant_data_keys(tenant_id, version DESC);
+
+-- Re-encryption runs after a data key rotation
+CREATE TABLE key_rotation_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    from_version INTEGER NOT NULL,
+    to_version INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    current_table VARCHAR(100),
+    last_id UUID,
+    rows_reencrypted INTEGER DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_key_rotation_jobs_tenant ON key_rotation_jobs(tenant_id, created_at DESC);
+CREATE INDEX idx_key_rotation_jobs_status ON key_rotation_jobs(status);
+
+-- ============================================================================
+-- ENCRYPTED COLUMNS & BLIND INDEXES
+-- ============================================================================
+
+-- Ciphertext envelopes are longer than the original values and IP addresses
+-- are no longer valid INET literals once encrypted.
+-- subject_index holds a tenant-scoped HMAC of subject_id for equality lookups.
+-- Rows written before this migration stay readable as plaintext; running a
+-- key rotation encrypts them and backfills subject_index.
+
+ALTER TABLE persona_verifications ALTER COLUMN subject_id TYPE TEXT;
+ALTER TABLE persona_verifications ADD COLUMN subject_index VARCHAR(64);
+DROP INDEX IF EXISTS idx_persona_tenant_subject;
+CREATE INDEX idx_persona_tenant_subject ON persona_verifications(tenant_id, subject_index);
+
+ALTER TABLE event_log ALTER COLUMN subject_id TYPE TEXT;
+ALTER TABLE event_log ALTER COLUMN ip_address TYPE TEXT USING host(ip_address);
+ALTER TABLE event_log ADD COLUMN subject_index VARCHAR(64);
+DROP INDEX IF EXISTS idx_event_log_subject;
+CREATE INDEX idx_event_log_subject ON event_log(tenant_id, subject_index);
+
+-- Ciphertext is randomized, so uniqueness on subject_id can no longer hold
+ALTER TABLE reputation_scores DROP CONSTRAINT IF EXISTS reputation_scores_tenant_id_subject_id_key;
+ALTER TABLE reputation_scores ALTER COLUMN subject_id TYPE TEXT;
+ALTER TABLE reputation_scores ADD COLUMN subject_index VARCHAR(64);
+DROP INDEX IF EXISTS idx_reputation_tenant_subject;
+CREATE INDEX idx_reputation_tenant_subject ON reputation_scores(tenant_id, subject_index);
@@ -0,0 +1,54 @@
+-- Mighty Eagle Trust Layer - Subject Erasure
+-- Right to be forgotten across all modules
+
+-- ============================================================================
+-- ERASURE JOBS
+-- ============================================================================
+
+-- The table is the job queue: runners on any replica lease due jobs with
+-- FOR UPDATE SKIP LOCKED, renew the lease with heartbeats, and reclaim jobs
+-- whose heartbeats stopped. Failed attempts are retried with backoff.
+CREATE TABLE subject_erasure_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subject_id TEXT, -- Encrypted, cleared once the job completes
+    subject_index VARCHAR(64) NOT NULL,
+    requested_by VARCHAR(255),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    summary JSONB DEFAULT '{}',
+    certificate JSONB, -- Signed erasure certificate
+    error_message TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(255), -- Runner holding the lease
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_subject_erasure_jobs_tenant ON subject_erasure_jobs(tenant_id, created_at DESC);
+CREATE INDEX idx_subject_erasure_jobs_subject ON subject_erasure_jobs(tenant_id, subject_index);
+CREATE INDEX idx_subject_erasure_jobs_due ON subject_erasure_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX idx_subject_erasure_jobs_leases ON subject_erasure_jobs(heartbeat_at) WHERE status = 'processing';
+
+-- ============================================================================
+-- TOMBSTONES
+-- ============================================================================
+
+-- One row per record removed or redacted by an erasure. content_digest is the
+-- SHA-256 of the original content so the audit trail stays verifiable.
+CREATE TABLE erasure_tombstones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    erasure_job_id UUID NOT NULL REFERENCES subject_erasure_jobs(id) ON DELETE CASCADE,
+    source_table VARCHAR(100) NOT NULL,
+    record_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('redacted', 'deleted')),
+    content_digest VARCHAR(64) NOT NULL,
+    erased_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_erasure_tombstones_job ON erasure_tombstones(erasure_job_id);
+CREATE INDEX idx_erasure_tombstones_record ON erasure_tombstones(source_table, record_id);
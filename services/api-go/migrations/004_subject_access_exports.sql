@@ -0,0 +1,34 @@
+-- Mighty Eagle Trust Layer - Subject Access Exports
+-- Machine-readable export of everything held about a data subject
+
+-- ============================================================================
+-- ACCESS EXPORTS
+-- ============================================================================
+
+-- Exports run from the subject job queue, leased like erasure jobs
+CREATE TABLE subject_access_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    bundle JSONB, -- Encrypted, purged once the download window expires
+    manifest JSONB, -- Signed manifest
+    record_count INTEGER,
+    file_size_bytes BIGINT,
+    error_message TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(255), -- Runner holding the lease
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_subject_access_exports_subject ON subject_access_exports(tenant_id, subject_index, created_at DESC);
+CREATE INDEX idx_subject_access_exports_expiry ON subject_access_exports(expires_at) WHERE bundle IS NOT NULL;
+CREATE INDEX idx_subject_access_exports_due ON subject_access_exports(run_after) WHERE status = 'pending';
+CREATE INDEX idx_subject_access_exports_leases ON subject_access_exports(heartbeat_at) WHERE status = 'processing';
@@ -0,0 +1,31 @@
+-- Mighty Eagle Trust Layer - Pairwise Pseudonyms
+-- Per-tenant pseudonyms for subject identifiers and provider proofs
+
+-- ============================================================================
+-- PSEUDONYM MAPPINGS
+-- ============================================================================
+
+-- pseudonym = "psn_" + HMAC(HMAC(PSEUDONYM_KEY, tenant_id), namespace:external_id)
+-- The same identifier maps to unrelated pseudonyms for different tenants.
+CREATE TABLE subject_pseudonyms (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    pseudonym VARCHAR(64) NOT NULL,
+    namespace VARCHAR(50) NOT NULL, -- subject, or the provider for proof identifiers
+    external_id TEXT NOT NULL, -- Encrypted
+    external_index VARCHAR(64) NOT NULL, -- Blind index of external_id
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, pseudonym)
+);
+
+CREATE INDEX idx_subject_pseudonyms_external ON subject_pseudonyms(tenant_id, namespace, external_index);
+
+-- ============================================================================
+-- PERSONA VERIFICATIONS
+-- ============================================================================
+
+-- proof_hash now holds the pseudonym of the provider proof (e.g. World ID nullifier)
+ALTER TABLE persona_verifications ADD COLUMN subject_pseudonym VARCHAR(64);
+
+CREATE INDEX idx_persona_verifications_pseudonym ON persona_verifications(tenant_id, subject_pseudonym);
+CREATE INDEX idx_persona_verifications_proof ON persona_verifications(tenant_id, proof_hash);
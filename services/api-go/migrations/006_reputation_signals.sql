@@ -0,0 +1,26 @@
+-- Mighty Eagle Trust Layer - Reputation Signals
+-- Typed positive and negative signals consumed by the scorer
+
+-- ============================================================================
+-- SIGNALS
+-- ============================================================================
+
+CREATE TABLE reputation_signals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    signal_type VARCHAR(50) NOT NULL CHECK (signal_type IN ('event_attended', 'no_show', 'report_upheld', 'dispute_resolved', 'endorsement')),
+    weight INTEGER NOT NULL,
+    source_type VARCHAR(50) NOT NULL CHECK (source_type IN ('tenant', 'subject', 'moderator', 'system')),
+    source_id TEXT, -- Encrypted
+    source_index VARCHAR(64),
+    idempotency_key VARCHAR(255) NOT NULL,
+    metadata JSONB DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, idempotency_key)
+);
+
+CREATE INDEX idx_reputation_signals_subject ON reputation_signals(tenant_id, subject_index, occurred_at DESC);
+CREATE INDEX idx_reputation_signals_source ON reputation_signals(tenant_id, source_index) WHERE source_index IS NOT NULL;
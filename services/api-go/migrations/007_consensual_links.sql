@@ -0,0 +1,55 @@
+-- Mighty Eagle Trust Layer - Consensual Links & Trust Badges
+-- Mutual, proof-backed links between subjects and the badges they award
+
+-- ============================================================================
+-- LINKS
+-- ============================================================================
+
+CREATE TABLE consensual_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    initiator_id TEXT NOT NULL, -- Encrypted
+    initiator_index VARCHAR(64) NOT NULL,
+    recipient_id TEXT NOT NULL, -- Encrypted
+    recipient_index VARCHAR(64) NOT NULL,
+    event_id VARCHAR(255),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'completed', 'expired', 'declined')),
+    initiator_signature JSONB, -- {signature, timestamp, nullifier_hash}
+    recipient_signature JSONB,
+    decline_reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    declined_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (initiator_index <> recipient_index)
+);
+
+CREATE INDEX idx_consensual_links_initiator ON consensual_links(tenant_id, initiator_index, created_at DESC);
+CREATE INDEX idx_consensual_links_recipient ON consensual_links(tenant_id, recipient_index, created_at DESC);
+CREATE INDEX idx_consensual_links_expiry ON consensual_links(expires_at) WHERE status IN ('pending', 'accepted');
+
+CREATE TRIGGER update_consensual_links_updated_at BEFORE UPDATE ON consensual_links
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- BADGES
+-- ============================================================================
+
+-- One badge per party of a completed link. The counterpart is only kept as a
+-- blind index so badges stay anonymous.
+CREATE TABLE trust_badges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    link_id UUID NOT NULL,
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    linked_subject_index VARCHAR(64),
+    event_context VARCHAR(255),
+    awarded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(link_id, subject_index)
+);
+
+CREATE INDEX idx_trust_badges_subject ON trust_badges(tenant_id, subject_index);
+CREATE INDEX idx_trust_badges_linked ON trust_badges(tenant_id, linked_subject_index) WHERE linked_subject_index IS NOT NULL;
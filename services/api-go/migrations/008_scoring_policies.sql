@@ -0,0 +1,80 @@
+-- Mighty Eagle Trust Layer - Scoring Policies
+-- Per-tenant, versioned scoring configuration. Versions are immutable; a
+-- tenant scores with the pinned version of its single active policy.
+
+-- ============================================================================
+-- POLICIES
+-- ============================================================================
+
+CREATE TABLE scoring_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    latest_version INTEGER NOT NULL DEFAULT 1,
+    active_version INTEGER,
+    is_active BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, name),
+    CHECK (NOT is_active OR active_version IS NOT NULL)
+);
+
+-- At most one active policy per tenant
+CREATE UNIQUE INDEX idx_scoring_policies_active ON scoring_policies(tenant_id) WHERE is_active;
+
+CREATE TRIGGER update_scoring_policies_updated_at BEFORE UPDATE ON scoring_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- VERSIONS
+-- ============================================================================
+
+CREATE TABLE scoring_policy_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES scoring_policies(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    config JSONB NOT NULL, -- Scorer weights, caps and level bands
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(policy_id, version)
+);
+
+-- Versions are append-only
+CREATE OR REPLACE FUNCTION prevent_scoring_policy_version_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'scoring policy versions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER scoring_policy_versions_immutable BEFORE UPDATE ON scoring_policy_versions
+    FOR EACH ROW EXECUTE FUNCTION prevent_scoring_policy_version_update();
+
+-- ============================================================================
+-- ACTIVATIONS
+-- ============================================================================
+
+-- Which version was active when, so backtests compare a candidate with what
+-- production scored at each date rather than with today's policy
+CREATE TABLE scoring_policy_activations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    policy_id UUID NOT NULL REFERENCES scoring_policies(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    activated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_scoring_policy_activations_tenant ON scoring_policy_activations(tenant_id, activated_at DESC);
+
+-- ============================================================================
+-- SCORE SNAPSHOTS
+-- ============================================================================
+
+-- Every snapshot records the level and policy version it was calculated under
+ALTER TABLE reputation_scores ADD COLUMN IF NOT EXISTS level VARCHAR(50);
+ALTER TABLE reputation_scores ADD COLUMN IF NOT EXISTS factors JSONB DEFAULT '{}';
+ALTER TABLE reputation_scores ADD COLUMN policy_id UUID REFERENCES scoring_policies(id) ON DELETE SET NULL;
+ALTER TABLE reputation_scores ADD COLUMN policy_version INTEGER;
+
+CREATE INDEX idx_reputation_policy ON reputation_scores(policy_id, policy_version) WHERE policy_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Mighty Eagle Trust Layer - Verification Expiry
+-- Supports the sweep that moves verified records past expires_at to 'expired'
+
+CREATE INDEX idx_persona_verified_expiry ON persona_verifications(expires_at) WHERE status = 'verified';
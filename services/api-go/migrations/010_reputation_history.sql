@@ -0,0 +1,4 @@
+-- Mighty Eagle Trust Layer - Reputation History
+-- Snapshots are only written when a score changes and are read back by time range
+
+CREATE INDEX idx_reputation_subject_history ON reputation_scores(tenant_id, subject_index, created_at DESC);
@@ -0,0 +1,32 @@
+-- Mighty Eagle Trust Layer - Signal Appeals
+-- Subjects appeal negative signals; a moderator upholds or overturns them.
+-- Overturned signals stay on record but drop out of scoring.
+
+ALTER TABLE reputation_signals ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'overturned'));
+ALTER TABLE reputation_signals ADD COLUMN overturned_at TIMESTAMP WITH TIME ZONE;
+
+-- ============================================================================
+-- APPEALS
+-- ============================================================================
+
+CREATE TABLE signal_appeals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    signal_id UUID NOT NULL REFERENCES reputation_signals(id) ON DELETE CASCADE,
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'upheld', 'overturned')),
+    reason TEXT NOT NULL, -- Encrypted
+    resolution_note TEXT,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(signal_id) -- One appeal per signal; the decision is final
+);
+
+CREATE INDEX idx_signal_appeals_queue ON signal_appeals(tenant_id, status, created_at);
+CREATE INDEX idx_signal_appeals_subject ON signal_appeals(tenant_id, subject_index);
+
+CREATE TRIGGER update_signal_appeals_updated_at BEFORE UPDATE ON signal_appeals
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- Mighty Eagle Trust Layer - Reputation Watches
+-- Threshold watches on one subject or every subject of a tenant. Crossings
+-- and level changes are recorded as reputation.* events and sent to webhooks.
+
+CREATE TABLE reputation_watches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subject_id TEXT, -- Encrypted; NULL watches every subject
+    subject_index VARCHAR(64),
+    threshold DECIMAL(5,2) NOT NULL CHECK (threshold >= 0 AND threshold <= 100),
+    direction VARCHAR(10) NOT NULL DEFAULT 'below' CHECK (direction IN ('below', 'above', 'both')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reputation_watches_subject ON reputation_watches(tenant_id, subject_index);
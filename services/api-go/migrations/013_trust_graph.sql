@@ -0,0 +1,54 @@
+-- Mighty Eagle Trust Layer - Trust Graph Anomalies
+-- A background analyzer reads endorsement and link edges incrementally and
+-- flags reciprocal rings, freshly verified sock puppets and edges between
+-- subjects sharing a verification nullifier. Open anomalies dampen the edges
+-- between their members in scoring until a moderator dismisses them.
+
+CREATE TABLE trust_anomalies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('reciprocal_ring', 'sock_puppets', 'shared_nullifier')),
+    fingerprint VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed')),
+    dampening DECIMAL(4,3) NOT NULL CHECK (dampening >= 0 AND dampening <= 1),
+    evidence JSONB NOT NULL DEFAULT '{}',
+    member_count INTEGER NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, fingerprint) -- A cluster is raised once; a dismissal sticks until its membership changes
+);
+
+CREATE INDEX idx_trust_anomalies_queue ON trust_anomalies(tenant_id, status, detected_at);
+
+CREATE TRIGGER update_trust_anomalies_updated_at BEFORE UPDATE ON trust_anomalies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE trust_anomaly_members (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    anomaly_id UUID NOT NULL REFERENCES trust_anomalies(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'target', 'source')),
+    UNIQUE(anomaly_id, subject_index)
+);
+
+CREATE INDEX idx_trust_anomaly_members_subject ON trust_anomaly_members(tenant_id, subject_index);
+
+-- ============================================================================
+-- ANALYZER STATE
+-- ============================================================================
+
+CREATE TABLE trust_graph_cursors (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    processed_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Edges recorded since the cursor
+CREATE INDEX idx_reputation_signals_created ON reputation_signals(tenant_id, created_at);
+CREATE INDEX idx_trust_badges_awarded ON trust_badges(tenant_id, awarded_at);
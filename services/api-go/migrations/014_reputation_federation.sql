@@ -0,0 +1,33 @@
+-- Mighty Eagle Trust Layer - Federated Reputation
+-- A subject consents at a source tenant, with a reputation.federation consent
+-- token naming the tenants allowed to read their reputation there. A reading
+-- tenant links its own subject to that consent; the source score then feeds
+-- the subject's score, weighted by how far the reader trusts the source.
+
+CREATE TABLE reputation_federation_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE, -- Reading tenant
+    subject_id TEXT NOT NULL, -- Encrypted
+    subject_index VARCHAR(64) NOT NULL,
+    source_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    source_subject_index VARCHAR(64) NOT NULL, -- Blind index under the source tenant
+    consent_token_id UUID NOT NULL REFERENCES consent_tokens(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, subject_index, source_tenant_id) -- One source subject per reader subject
+);
+
+CREATE INDEX idx_reputation_federation_links_source ON reputation_federation_links(source_tenant_id, source_subject_index);
+CREATE INDEX idx_reputation_federation_links_consent ON reputation_federation_links(consent_token_id);
+
+CREATE TABLE reputation_federation_trust (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    source_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    weight DECIMAL(4,3) NOT NULL CHECK (weight >= 0 AND weight <= 1),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, source_tenant_id)
+);
+
+CREATE TRIGGER update_reputation_federation_trust_updated_at BEFORE UPDATE ON reputation_federation_trust
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
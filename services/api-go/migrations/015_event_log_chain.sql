@@ -0,0 +1,65 @@
+-- Mighty Eagle Trust Layer - Tamper-Evident Event Log
+-- Each tenant's events form a hash chain: an event's hash covers its
+-- canonical content and the previous event's hash, so editing, removing or
+-- reordering any event breaks every later link. Personal fields enter the
+-- hash through a keyed content digest, which lets subject erasure redact them
+-- and key rotation re-encrypt them without breaking the chain.
+--
+-- Events logged before this migration have no sequence and are not chained.
+
+ALTER TABLE event_log ADD COLUMN sequence BIGINT;
+ALTER TABLE event_log ADD COLUMN prev_hash VARCHAR(64);
+ALTER TABLE event_log ADD COLUMN hash VARCHAR(64);
+ALTER TABLE event_log ADD COLUMN content_digest VARCHAR(64);
+
+CREATE UNIQUE INDEX idx_event_log_chain ON event_log(tenant_id, sequence) WHERE sequence IS NOT NULL;
+
+-- Latest link of each tenant's chain. Writers lock the row to append in order.
+CREATE TABLE event_log_chain_heads (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    sequence BIGINT NOT NULL DEFAULT 0,
+    hash VARCHAR(64) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================================================
+-- APPEND-ONLY ENFORCEMENT
+-- ============================================================================
+
+-- Only the personal fields may change after insert (erasure and re-encryption);
+-- everything the chain covers is frozen, and rows are never deleted. Tenants
+-- are closed by status rather than deleted, so their cascade never gets here.
+CREATE OR REPLACE FUNCTION event_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND NEW.id = OLD.id
+       AND NEW.tenant_id = OLD.tenant_id
+       AND NEW.event_type = OLD.event_type
+       AND NEW.event_version = OLD.event_version
+       AND NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id
+       AND NEW.resource_type IS NOT DISTINCT FROM OLD.resource_type
+       AND NEW.resource_id IS NOT DISTINCT FROM OLD.resource_id
+       AND NEW.created_at = OLD.created_at
+       AND NEW.sequence IS NOT DISTINCT FROM OLD.sequence
+       AND NEW.prev_hash IS NOT DISTINCT FROM OLD.prev_hash
+       AND NEW.hash IS NOT DISTINCT FROM OLD.hash
+       AND NEW.content_digest IS NOT DISTINCT FROM OLD.content_digest THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'event_log is append-only: % of event % rejected', TG_OP, OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER event_log_append_only BEFORE UPDATE OR DELETE ON event_log
+    FOR EACH ROW EXECUTE FUNCTION event_log_append_only();
+
+CREATE OR REPLACE FUNCTION event_log_no_truncate()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'event_log is append-only: TRUNCATE rejected';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER event_log_no_truncate BEFORE TRUNCATE ON event_log
+    FOR EACH STATEMENT EXECUTE FUNCTION event_log_no_truncate();
@@ -0,0 +1,22 @@
+-- Mighty Eagle Trust Layer - Audit Checkpoints
+-- Periodically, each tenant's events since the previous checkpoint are
+-- committed to an RFC 6962 Merkle tree over their chain hashes. The root is
+-- signed with the server key, so a single event can later be proven to have
+-- existed at checkpoint time with a short inclusion proof.
+
+CREATE TABLE audit_checkpoints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    from_sequence BIGINT NOT NULL,
+    to_sequence BIGINT NOT NULL,
+    tree_size BIGINT NOT NULL CHECK (tree_size = to_sequence - from_sequence + 1),
+    merkle_root VARCHAR(64) NOT NULL,
+    chain_hash VARCHAR(64) NOT NULL, -- Hash of the event at to_sequence
+    previous_id UUID REFERENCES audit_checkpoints(id),
+    statement TEXT NOT NULL, -- Exactly the signed bytes
+    signature JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, from_sequence)
+);
+
+CREATE INDEX idx_audit_checkpoints_range ON audit_checkpoints(tenant_id, to_sequence DESC);
@@ -0,0 +1,15 @@
+-- Mighty Eagle Trust Layer - Audit Event Queries
+-- Events are paged by keyset on (created_at, id) instead of OFFSET, so deep
+-- pages cost the same as the first.
+--
+-- Metadata is encrypted at rest and cannot be searched with jsonb operators.
+-- Each event instead carries blind indexes of its metadata's (path, value)
+-- pairs; a containment filter becomes array containment over those. Events
+-- logged before this migration have no metadata index and never match a
+-- metadata filter.
+
+ALTER TABLE event_log ADD COLUMN metadata_index TEXT[];
+
+CREATE INDEX idx_event_log_keyset ON event_log(tenant_id, created_at DESC, id DESC);
+CREATE INDEX idx_event_log_actor ON event_log(tenant_id, actor_id, created_at DESC);
+CREATE INDEX idx_event_log_metadata ON event_log USING GIN (metadata_index);
@@ -0,0 +1,11 @@
+-- Mighty Eagle Trust Layer - Audit Export Storage
+-- Exports are streamed to a blob store (local disk or S3-compatible) instead
+-- of being held in memory and inlined into the job row. Download links are
+-- signed on read, so the stored download_url column goes away; the data URIs
+-- it held were never valid downloads.
+
+ALTER TABLE audit_export_jobs DROP COLUMN download_url;
+ALTER TABLE audit_export_jobs ADD COLUMN storage_key TEXT;
+ALTER TABLE audit_export_jobs ADD COLUMN compression VARCHAR(20) CHECK (compression IN ('gzip'));
+
+CREATE INDEX idx_audit_exports_expiry ON audit_export_jobs(expires_at) WHERE storage_key IS NOT NULL;
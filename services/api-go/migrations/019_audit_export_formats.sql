@@ -0,0 +1,6 @@
+-- Mighty Eagle Trust Layer - Audit Export Formats
+-- Export formats are registered in the audit service (csv, json, ndjson,
+-- parquet, ocsf) and validated there, so the column no longer pins the list.
+
+ALTER TABLE audit_export_jobs DROP CONSTRAINT IF EXISTS audit_export_jobs_format_check;
+ALTER TABLE audit_export_jobs ADD CONSTRAINT audit_export_jobs_format_check CHECK (format ~ '^[a-z0-9_]+$');
@@ -0,0 +1,24 @@
+-- Mighty Eagle Trust Layer - Audit Export Queue
+-- Export jobs used to run on a goroutine of the replica that accepted them,
+-- so a restart left them pending forever. The table is now the queue:
+-- runners on any replica lease due jobs with FOR UPDATE SKIP LOCKED, renew
+-- the lease with heartbeats, and reclaim jobs whose heartbeats stopped.
+-- Failed attempts are retried with exponential backoff.
+
+ALTER TABLE audit_export_jobs DROP CONSTRAINT IF EXISTS audit_export_jobs_status_check;
+ALTER TABLE audit_export_jobs ADD CONSTRAINT audit_export_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE audit_export_jobs ADD COLUMN progress_percent SMALLINT NOT NULL DEFAULT 0 CHECK (progress_percent BETWEEN 0 AND 100);
+ALTER TABLE audit_export_jobs ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE audit_export_jobs ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 5;
+ALTER TABLE audit_export_jobs ADD COLUMN run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE audit_export_jobs ADD COLUMN locked_by VARCHAR(255);
+ALTER TABLE audit_export_jobs ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE audit_export_jobs ADD COLUMN started_at TIMESTAMP WITH TIME ZONE;
+
+-- Jobs orphaned by the old in-process runner are queued again
+UPDATE audit_export_jobs SET status = 'pending' WHERE status = 'processing';
+
+CREATE INDEX idx_audit_exports_due ON audit_export_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX idx_audit_exports_leases ON audit_export_jobs(heartbeat_at) WHERE status = 'processing';
@@ -0,0 +1,21 @@
+-- Mighty Eagle Trust Layer - Audit Export Manifests & Encryption
+-- Each completed export carries a manifest signed with the server key: the
+-- SHA-256 of the file as downloaded, its record count, time range, filters
+-- and the tenant's chain head, so auditors can show the file was not altered.
+--
+-- Tenants may register a public key (age X25519 or OpenPGP RSA); their
+-- exports are then encrypted to it before storage and only they can read them.
+
+ALTER TABLE audit_export_jobs ADD COLUMN file_sha256 VARCHAR(64);
+ALTER TABLE audit_export_jobs ADD COLUMN encryption VARCHAR(20) CHECK (encryption IN ('age', 'openpgp'));
+ALTER TABLE audit_export_jobs ADD COLUMN manifest TEXT; -- Exactly the signed bytes
+ALTER TABLE audit_export_jobs ADD COLUMN manifest_signature JSONB;
+
+CREATE TABLE tenant_export_keys (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    key_type VARCHAR(20) NOT NULL CHECK (key_type IN ('age', 'openpgp')),
+    public_key TEXT NOT NULL,
+    fingerprint VARCHAR(255) NOT NULL, -- age recipient or OpenPGP key fingerprint
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);